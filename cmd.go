@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/abema/kubetool/kube"
+	"github.com/abema/kubetool/registry"
 	"github.com/alecthomas/kingpin"
 	"github.com/fatih/color"
 )
@@ -15,20 +17,40 @@ var (
 	app       = kingpin.New("kubetool", "kubernetes bulk task executor.")
 	verbose   = app.Flag("verbose", "Enable verbose log.").Short('v').Bool()
 	namespace = app.Flag("namespace", "Target namespace. default is all namespaces").String()
+	allNS     = app.Flag("all-namespaces", "Target all namespaces. Same as --namespace=all").Bool()
 	yes       = app.Flag("yes", "Skip confirmation.").Short('y').Bool()
+	output    = app.Flag("output", "Output format of listings. One of json|yaml|csv|wide.").Short('o').Enum(kube.OutputFormats...)
+	tmpl      = app.Flag("template", "Go template to print each rc/pod of listings. ex) '{{.Name}} {{.Image.Tag}}'").String()
+	columns   = app.Flag("columns", "Custom columns of listings. ex) NAME:.metadata.name,NODE:.spec.nodeName").String()
+	dryRun    = app.Flag("dry-run", "Print planned patches, pod deletions and kubectl commands without executing them.").Bool()
 	force     = app.Flag("force", "Force reload pods. Ignores pod status while reloading.").Short('f').Bool()
 	interval  = app.Flag("interval", "Reloading interval on restarting each pod.").Default("0").Int()
 	minStable = app.Flag("min-stable", "Minimum value of available pod percentage to detect RC is stable or not. (0.0-1.0). Defalt is 0.8").Default("0.8").Float64()
+	insecure  = app.Flag("insecure-registry", "Access image registry via plain http.").Bool()
+	dockerCfg = app.Flag("docker-config", "Docker config file to read registry credentials.").Default(registry.DefaultDockerConfig()).String()
 
 	// command info
 	info = app.Command("info", "Print cluster & version info about cluster.").Alias("i")
 
 	// command rc
-	rc = app.Command("rc", "Print all rc.")
+	rc         = app.Command("rc", "Print all rc.")
+	rcSortBy   = rc.Flag("sort-by", "Sort rows by name|age|version.").Enum(kube.SortByName, kube.SortByAge, kube.SortByVersion)
+	rcImage    = rc.Flag("image", "Show only rows which image name contains this.").String()
+	rcVersion  = rc.Flag("image-version", "Show only rows with this image version.").String()
+	rcNotReady = rc.Flag("not-ready", "Show only rc lacking ready pods.").Bool()
+	rcWatch    = rc.Flag("watch", "Redraw listing on changes.").Short('w').Bool()
 
 	// command pod
-	pod   = app.Command("pod", "Print all pods").Alias("pods").Alias("po")
-	podRC = pod.Flag("rc", "rc name for pod target").String()
+	pod         = app.Command("pod", "Print all pods").Alias("pods").Alias("po")
+	podRC       = pod.Flag("rc", "rc name for pod target").String()
+	podSortBy   = pod.Flag("sort-by", "Sort rows by name|restarts|age|node|version.").Enum(kube.SortByName, kube.SortByRestarts, kube.SortByAge, kube.SortByNode, kube.SortByVersion)
+	podStatus   = pod.Flag("status", "Show only containers in status Running|Waiting|Terminated.").Enum("Running", "Waiting", "Terminated")
+	podNode     = pod.Flag("node", "Show only pods on this node name or node IP.").String()
+	podImage    = pod.Flag("image", "Show only rows which image name contains this.").String()
+	podVersion  = pod.Flag("image-version", "Show only rows with this image version.").String()
+	podNotReady = pod.Flag("not-ready", "Show only containers not ready.").Bool()
+	podRestarts = pod.Flag("restarts-gt", "Show only containers restarted more than N times.").Default("-1").Int()
+	podWatch    = pod.Flag("watch", "Redraw listing on changes.").Short('w').Bool()
 
 	// command reload
 	reload     = app.Command("reload", "Reload all pods in rc.")
@@ -42,6 +64,122 @@ var (
 	updateReload    = update.Flag("reload", "Reload pods after update.").Bool()
 	updateReloadOne = update.Flag("1", "Reload only 1 pod after update.").Short('1').Bool()
 	updateContainer = update.Flag("container", "Target container name. Default is first container in defs.").Short('c').String()
+	updatePinDigest = update.Flag("pin-digest", "Resolve version tag to digest via registry and pin image with it.").Bool()
+	updateBump      = update.Flag("bump", "Update to the newest patch, minor or major version of current.").Enum(registry.BumpPatch, registry.BumpMinor, registry.BumpMajor)
+	updateMatching  = update.Flag("to-latest-matching", "Update to the newest version matching pattern like 1.9.x").String()
+	updatePre       = update.Flag("pre", "Allow pre release versions on --bump or --to-latest-matching.").Bool()
+	updateDowngrade = update.Flag("allow-downgrade", "Allow computed version older than current.").Bool()
+	updateTags      = update.Flag("candidates", "Comma separated candidate versions instead of registry tags.").Strings()
+
+	// command set-env
+	setEnv          = app.Command("set-env", "Set or remove environment variables of rc container.")
+	setEnvName      = setEnv.Arg("rc-name", "Name of target RC.").Required().String()
+	setEnvVars      = setEnv.Arg("env", "KEY=VAL to set or KEY- to remove.").Required().Strings()
+	setEnvContainer = setEnv.Flag("container", "Target container name. Default is first container in defs.").Short('c').String()
+	setEnvReload    = setEnv.Flag("reload", "Reload pods after update.").Bool()
+	setEnvReloadOne = setEnv.Flag("1", "Reload only 1 pod after update.").Short('1').Bool()
+
+	// command set-resources
+	setRes          = app.Command("set-resources", "Set or remove resource requests/limits of rc container.")
+	setResName      = setRes.Arg("rc-name", "Name of target RC.").Required().String()
+	setResRequests  = setRes.Flag("requests", "Resource requests like cpu=200m,memory=512Mi. NAME- to remove.").Strings()
+	setResLimits    = setRes.Flag("limits", "Resource limits like cpu=1,memory=1Gi. NAME- to remove.").Strings()
+	setResContainer = setRes.Flag("container", "Target container name. Default is first container in defs.").Short('c').String()
+	setResReload    = setRes.Flag("reload", "Reload pods after update.").Bool()
+	setResReloadOne = setRes.Flag("1", "Reload only 1 pod after update.").Short('1').Bool()
+
+	// command diff
+	diff     = app.Command("diff", "Show differences between live rc and manifest file.")
+	diffFile = diff.Flag("filename", "RC manifest file (yaml or json). No -f short flag, which is --force.").Required().String()
+
+	// command sync
+	sync          = app.Command("sync", "Patch live rc to match manifest file.")
+	syncFile      = sync.Flag("filename", "RC manifest file (yaml or json). No -f short flag, which is --force.").Required().String()
+	syncReload    = sync.Flag("reload", "Reload pods after sync.").Bool()
+	syncReloadOne = sync.Flag("1", "Reload only 1 pod after sync.").Short('1').Bool()
+
+	// command drift
+	drift = app.Command("drift", "Report pods running images different from rc, and rc lacking replicas. Exit with 2 on drift.")
+
+	// command images
+	images      = app.Command("images", "Print inventory of images in rc and running pods.")
+	imagesImage = images.Flag("image", "Show only images which name contains this.").String()
+
+	// command crashes
+	crashes   = app.Command("crashes", "Print crashing containers ranked by restarts per rc.")
+	crashesRC = crashes.Flag("rc", "rc name for pod target").String()
+
+	// command events
+	events       = app.Command("events", "Print events of rc and its pods.")
+	eventsName   = events.Arg("rc-name", "Name of target RC.").Required().String()
+	eventsFollow = events.Flag("follow", "Stream new events.").Bool()
+
+	// command logs
+	logs          = app.Command("logs", "Print logs of all pods in rc.")
+	logsName      = logs.Arg("rc-name", "Name of target RC.").Required().String()
+	logsContainer = logs.Flag("container", "Target container name. Default is first container in defs.").Short('c').String()
+	logsFollow    = logs.Flag("follow", "Stream logs including pods created later.").Bool()
+	logsSince     = logs.Flag("since", "Only logs newer than duration like 10m.").Duration()
+	logsTail      = logs.Flag("tail", "Lines of recent logs of each pod. Default is all.").Default("-1").Int64()
+	logsPrevious  = logs.Flag("previous", "Logs of previous terminated containers.").Bool()
+	logsGrep      = logs.Flag("grep", "Only lines matching regular expression.").String()
+
+	// command exec
+	execCmd       = app.Command("exec", "Run command in all pods of rc. Exit with 2 when any pod failed.")
+	execName      = execCmd.Arg("rc-name", "Name of target RC.").Required().String()
+	execCommand   = execCmd.Arg("command", "Command and arguments after --.").Required().Strings()
+	execContainer = execCmd.Flag("container", "Target container name. Default is first container in defs.").Short('c').String()
+	execParallel  = execCmd.Flag("parallel", "Number of pods to run command at once.").Default("5").Int()
+
+	// command nodes
+	nodes   = app.Command("nodes", "Print conditions, resources and pods of nodes.")
+	nodesRC = nodes.Flag("rc", "Print how pods of this rc are spread across nodes and zones.").String()
+
+	// command drain
+	drain          = app.Command("drain", "Cordon node and evict its pods one by one keeping rc stable.")
+	drainNode      = drain.Arg("node", "Name of target node.").Required().String()
+	drainUnmanaged = drain.Flag("delete-unmanaged", "Also evict pods not owned by rc. They are lost.").Bool()
+
+	// command uncordon
+	uncordon     = app.Command("uncordon", "Mark node schedulable again.")
+	uncordonNode = uncordon.Arg("node", "Name of target node.").Required().String()
+
+	// command rebalance
+	rebalance        = app.Command("rebalance", "Delete pods of rc on overloaded nodes one by one to spread them evenly.")
+	rebalanceName    = rebalance.Arg("rc-name", "Name of target RC.").Required().String()
+	rebalanceMaxSkew = rebalance.Flag("max-skew", "Maximum difference of pods between nodes and between zones.").Default("1").Int()
+
+	// command svc
+	svc = app.Command("svc", "Print services with endpoints and rc behind them, flagging mismatches.")
+
+	// command orphans
+	orphans       = app.Command("orphans", "Report pods not matched by any rc, and rc with overlapping selectors.")
+	orphansDelete = orphans.Flag("delete", "Delete orphan pods after confirmation.").Bool()
+
+	// command reload-consumers
+	reloadConsumers       = app.Command("reload-consumers", "Reload all rc referencing configmap or secret.")
+	reloadConsumersTarget = reloadConsumers.Arg("target", "configmap/<name> or secret/<name>.").Required().String()
+	reloadConsumersList   = reloadConsumers.Flag("list", "Only list rc referencing target. Output format is applied.").Bool()
+
+	// command configmap
+	configmap = app.Command("configmap", "Manage configmap.")
+
+	// command configmap sync
+	cmSync       = configmap.Command("sync", "Create or replace configmap with files in directory.")
+	cmSyncName   = cmSync.Arg("name", "Name of target configmap.").Required().String()
+	cmSyncDir    = cmSync.Arg("dir", "Directory of config files.").Required().ExistingDir()
+	cmSyncReload = cmSync.Flag("reload", "Reload rc referencing configmap after sync.").Bool()
+
+	// command secrets
+	secrets = app.Command("secrets", "Inspect secrets.")
+
+	// command secrets audit
+	secretsAudit       = secrets.Command("audit", "Map secrets to rc and pods referencing them, flagging unused and missing ones.")
+	secretsAuditShow   = secretsAudit.Flag("show-values", "Print values of secrets, masked unless --unmask is given.").Bool()
+	secretsAuditUnmask = secretsAudit.Flag("unmask", "Print values of secrets as is with --show-values.").Bool()
+
+	// command ui
+	ui = app.Command("ui", "Full-screen dashboard of rc and pods.")
 
 	fixVersion     = app.Command("fix-version", "Fix all pods to destroy all that has different version of RC ones.")
 	fixVersionName = fixVersion.Arg("rc-name", "Name of target RC.").Required().String()
@@ -57,12 +195,23 @@ func main() {
 
 	ktool := kube.Tool{}
 	ktool.SetYes(*yes)
+	ktool.SetDryRun(*dryRun)
+	ktool.SetOutput(*output)
+	ktool.SetTemplate(*tmpl)
+	ktool.SetColumns(*columns)
 	ktool.SetForce(*force)
 	ktool.SetInterval(*interval)
+	ktool.SetInsecureRegistry(*insecure)
+	if err := ktool.SetDockerConfig(*dockerCfg); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintln(os.Stderr, red("failed to read docker config: "+err.Error()))
+	}
 
 	if namespace != nil {
 		ktool.SetNamespace(*namespace)
 	}
+	if *allNS {
+		ktool.SetNamespace("all")
+	}
 
 	if *minStable < 0 || *minStable > 1 {
 		fmt.Fprintln(os.Stderr, "minimum stable rate must be in range of 0.0-1.0")
@@ -75,13 +224,36 @@ func main() {
 	case info.FullCommand():
 		err = ktool.PrintInfo()
 	case rc.FullCommand():
-		err = ktool.PrintRCList()
+		ktool.SetFilter(kube.ListFilter{
+			SortBy:   *rcSortBy,
+			Image:    *rcImage,
+			Version:  *rcVersion,
+			NotReady: *rcNotReady,
+		})
+		if *rcWatch {
+			err = ktool.WatchRCList()
+		} else {
+			err = ktool.PrintRCList()
+		}
 	case pod.FullCommand():
 		rcname := ""
 		if podRC != nil {
 			rcname = *podRC
 		}
-		err = ktool.PrintPodList(rcname)
+		ktool.SetFilter(kube.ListFilter{
+			SortBy:      *podSortBy,
+			Status:      *podStatus,
+			Node:        *podNode,
+			Image:       *podImage,
+			Version:     *podVersion,
+			NotReady:    *podNotReady,
+			MinRestarts: *podRestarts + 1,
+		})
+		if *podWatch {
+			err = ktool.WatchPodList(rcname)
+		} else {
+			err = ktool.PrintPodList(rcname)
+		}
 	case reload.FullCommand():
 		err = ktool.Reload(*reloadName, *reloadOne)
 	case update.FullCommand():
@@ -89,10 +261,91 @@ func main() {
 		if updateContainer != nil {
 			container = *updateContainer
 		}
-		err = ktool.Update(*updateName, container, *updateVersion)
+		ktool.SetPinDigest(*updatePinDigest)
+		ktool.SetAllowDowngrade(*updateDowngrade)
+		if *updateBump != "" || *updateMatching != "" {
+			if *updateVersion != "" {
+				fmt.Fprintln(os.Stderr, "version can not be used with --bump or --to-latest-matching")
+				os.Exit(1)
+			}
+			c := registry.Constraint{Bump: *updateBump, Match: *updateMatching, Pre: *updatePre}
+			err = ktool.UpdateTo(*updateName, container, c, splitList(*updateTags))
+		} else {
+			err = ktool.Update(*updateName, container, *updateVersion)
+		}
 		if *updateReload && err == nil {
 			err = ktool.Reload(*updateName, *updateReloadOne)
 		}
+	case setEnv.FullCommand():
+		err = ktool.UpdateEnv(*setEnvName, *setEnvContainer, *setEnvVars)
+		if *setEnvReload && err == nil {
+			err = ktool.Reload(*setEnvName, *setEnvReloadOne)
+		}
+	case setRes.FullCommand():
+		err = ktool.UpdateResources(*setResName, *setResContainer, splitList(*setResRequests), splitList(*setResLimits))
+		if *setResReload && err == nil {
+			err = ktool.Reload(*setResName, *setResReloadOne)
+		}
+	case diff.FullCommand():
+		err = ktool.Diff(*diffFile)
+	case sync.FullCommand():
+		err = ktool.Sync(*syncFile, *syncReload, *syncReloadOne)
+	case drift.FullCommand():
+		var found bool
+		found, err = ktool.Drift()
+		if found && err == nil {
+			os.Exit(2)
+		}
+	case images.FullCommand():
+		err = ktool.PrintImages(*imagesImage)
+	case crashes.FullCommand():
+		err = ktool.PrintCrashes(*crashesRC)
+	case events.FullCommand():
+		err = ktool.Events(*eventsName, *eventsFollow)
+	case logs.FullCommand():
+		opts := kube.PodLogOptions{Container: *logsContainer, Follow: *logsFollow, Previous: *logsPrevious}
+		if *logsSince > 0 {
+			since := int64(logsSince.Seconds())
+			opts.SinceSeconds = &since
+		}
+		if *logsTail >= 0 {
+			opts.TailLines = logsTail
+		}
+		err = ktool.Logs(*logsName, opts, *logsGrep)
+	case execCmd.FullCommand():
+		var failed bool
+		failed, err = ktool.Exec(*execName, *execContainer, *execParallel, *execCommand)
+		if failed && err == nil {
+			os.Exit(2)
+		}
+	case nodes.FullCommand():
+		if *nodesRC != "" {
+			err = ktool.PrintPlacement(*nodesRC)
+		} else {
+			err = ktool.PrintNodes()
+		}
+	case drain.FullCommand():
+		err = ktool.Drain(*drainNode, *drainUnmanaged)
+	case uncordon.FullCommand():
+		err = ktool.Uncordon(*uncordonNode)
+	case rebalance.FullCommand():
+		err = ktool.Rebalance(*rebalanceName, *rebalanceMaxSkew)
+	case svc.FullCommand():
+		err = ktool.PrintServices()
+	case orphans.FullCommand():
+		err = ktool.PrintOrphans(*orphansDelete)
+	case reloadConsumers.FullCommand():
+		if *reloadConsumersList {
+			err = ktool.ListConsumers(*reloadConsumersTarget)
+		} else {
+			err = ktool.ReloadConsumers(*reloadConsumersTarget)
+		}
+	case cmSync.FullCommand():
+		err = ktool.SyncConfigMap(*cmSyncName, *cmSyncDir, *cmSyncReload)
+	case secretsAudit.FullCommand():
+		err = ktool.AuditSecrets(*secretsAuditShow, *secretsAuditUnmask)
+	case ui.FullCommand():
+		err = ktool.UI()
 	case fixVersion.FullCommand():
 		err = ktool.FixVersion(*fixVersionName)
 	}
@@ -102,3 +355,15 @@ func main() {
 	}
 
 }
+
+// splitList flattens comma separated values.
+func splitList(values []string) (list []string) {
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,131 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// configMapKey is valid key of config map data.
+var configMapKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// SyncConfigMap builds data of config map from regular files in dir, prints
+// unified diff of each key against live config map, then creates or replaces
+// it after confirmation. RCs referencing it are reloaded when reload is set.
+func (t *Tool) SyncConfigMap(name string, dir string, reload bool) (err error) {
+	if t.kubectl.Namespace == "all" {
+		return errors.New("config map is synced in single namespace")
+	}
+	data, err := readConfigDir(dir)
+	if err != nil {
+		return
+	}
+	live, found, err := t.kubectl.ConfigMap(name)
+	if err != nil {
+		return
+	}
+	t.PrintContext()
+	log("configmap:", blue(name))
+	log("directory:", blue(dir), gray("(%d files)", len(data)))
+	if !found {
+		log(yellow("configmap does not exist and will be created."))
+	}
+
+	changed := false
+	for _, key := range dataKeys(live.Data, data) {
+		before, ok := live.Data[key]
+		after, exist := data[key]
+		if ok && exist && before == after {
+			continue
+		}
+		changed = true
+		from, to := "live/"+key, "local/"+key
+		if !ok {
+			from = "/dev/null"
+		}
+		if !exist {
+			to = "/dev/null"
+		}
+		if _, err = printFileDiff(from, to, dataLines(before), dataLines(after)); err != nil {
+			return
+		}
+	}
+	if found && !changed {
+		log(green("configmap is in sync with directory."))
+		return
+	}
+	t.confirm("continue?")
+
+	cm := live
+	if !found {
+		cm.Kind, cm.APIVersion, cm.Name = "ConfigMap", "v1", name
+	}
+	cm.Data = data
+	if err = t.kubectl.SaveConfigMap(cm, found); err != nil {
+		return
+	}
+	if t.dryRun {
+		log(yellow("[dry-run] configmap is not saved"))
+	} else {
+		log(green("Successfully saved configmap"))
+	}
+	if reload {
+		err = t.ReloadConsumers("configmap/" + name)
+	}
+	return
+}
+
+// readConfigDir reads regular files in dir as config map data keyed by file name.
+// Subdirectories are ignored like kubectl create configmap --from-file does.
+func readConfigDir(dir string) (data map[string]string, err error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	data = map[string]string{}
+	for _, f := range files {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if !configMapKey.MatchString(f.Name()) {
+			return nil, fmt.Errorf("%s is not valid key of configmap", f.Name())
+		}
+		var b []byte
+		if b, err = ioutil.ReadFile(filepath.Join(dir, f.Name())); err != nil {
+			return
+		}
+		if !utf8.Valid(b) {
+			return nil, fmt.Errorf("%s is not UTF-8 text", f.Name())
+		}
+		data[f.Name()] = string(b)
+	}
+	return
+}
+
+// dataKeys returns sorted keys of both live and local data.
+func dataKeys(live map[string]string, local map[string]string) []string {
+	keys := []string{}
+	for k := range live {
+		keys = append(keys, k)
+	}
+	for k := range local {
+		if _, ok := live[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dataLines splits value into lines for diff.
+func dataLines(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(v, "\n"), "\n")
+}
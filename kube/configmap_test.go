@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubetool-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("worker_processes 1;\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".env"), []byte("A=1"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf.d"), 0755))
+
+	data, err := readConfigDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nginx.conf": "worker_processes 1;\n", ".env": "A=1"}, data)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bad key"), []byte(""), 0644))
+	_, err = readConfigDir(dir)
+	assert.Error(t, err)
+}
+
+func TestDataKeys(t *testing.T) {
+	keys := dataKeys(map[string]string{"b": "", "c": ""}, map[string]string{"a": "", "b": ""})
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Equal(t, []string{"a", "b"}, dataLines("a\nb\n"))
+	assert.Nil(t, dataLines(""))
+}
@@ -0,0 +1,157 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buger/goterm"
+)
+
+// ConsumerRow is a RC referencing ConfigMap or Secret.
+type ConsumerRow struct {
+	// Namespace of RC.
+	Namespace string `json:"namespace"`
+	// RC name.
+	RC string `json:"rc"`
+	// References in pod template like "volume config" or "env DB_PASSWORD in web".
+	References []string `json:"references"`
+}
+
+// ListConsumers prints RCs referencing ConfigMap or Secret in their pod
+// template. target is like configmap/name or secret/name.
+func (t *Tool) ListConsumers(target string) (err error) {
+	kind, name, err := parseConsumed(target)
+	if err != nil {
+		return
+	}
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	rows := consumerRows(rcs, kind, name)
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	t.printConsumers(kind, name, rows)
+	return
+}
+
+// ReloadConsumers reloads all RCs referencing ConfigMap or Secret in their pod
+// template, one RC after another with the same stability rules as reload.
+// target is like configmap/name or secret/name. Consumers are always printed
+// as table, since output format is for ListConsumers.
+func (t *Tool) ReloadConsumers(target string) (err error) {
+	kind, name, err := parseConsumed(target)
+	if err != nil {
+		return
+	}
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	rows := consumerRows(rcs, kind, name)
+	if !t.printConsumers(kind, name, rows) {
+		return
+	}
+	t.confirm(fmt.Sprintf("reload %d rc(s)?", len(rows)))
+
+	for _, r := range rows {
+		nt := t.in(r.Namespace)
+		var rc ReplicationController
+		if rc, err = nt.kubectl.RC(r.RC); err != nil {
+			return
+		}
+		var pods []Pod
+		if pods, err = nt.kubectl.PodList(rc.Spec.Selector); err != nil {
+			return
+		}
+		log("rc      :", blue(r.RC), gray("(%d pods)", len(pods)))
+		if len(pods) == 0 {
+			continue
+		}
+		if err = nt.reloadPods(rc, pods); err != nil {
+			return fmt.Errorf("reloading rc %s: %s", r.RC, err)
+		}
+	}
+	return
+}
+
+// printConsumers prints table of consumers. It returns false when none is found.
+func (t *Tool) printConsumers(kind string, name string, rows []ConsumerRow) bool {
+	t.PrintContext()
+	log("target  :", blue("%s/%s", kind, name))
+	if len(rows) == 0 {
+		log(yellow("no rc references %s/%s.", kind, name))
+		return false
+	}
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NAMESPACE\tRC\tREFERENCES\n")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Namespace, r.RC, strings.Join(r.References, ", "))
+	}
+	fmt.Fprintln(out, w.String())
+	return true
+}
+
+// parseConsumed parses target like configmap/name or secret/name.
+func parseConsumed(target string) (kind string, name string, err error) {
+	parts := strings.SplitN(target, "/", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		switch strings.ToLower(parts[0]) {
+		case "configmap", "configmaps", "cm":
+			return "configmap", parts[1], nil
+		case "secret", "secrets":
+			return "secret", parts[1], nil
+		}
+	}
+	err = fmt.Errorf("%s is not configmap/<name> nor secret/<name>", target)
+	return
+}
+
+// consumerRows returns RCs whose pod template references ConfigMap or Secret.
+func consumerRows(rcs []ReplicationController, kind string, name string) []ConsumerRow {
+	rows := []ConsumerRow{}
+	for _, rc := range rcs {
+		if rc.Spec.Template == nil {
+			continue
+		}
+		if refs := templateReferences(rc.Spec.Template.Spec, kind, name); len(refs) > 0 {
+			rows = append(rows, ConsumerRow{Namespace: rc.Namespace, RC: rc.Name, References: refs})
+		}
+	}
+	return rows
+}
+
+// templateReferences lists references to ConfigMap or Secret in pod spec
+// by volumes, environment variables and image pull secrets.
+func templateReferences(spec PodSpec, kind string, name string) (refs []string) {
+	for _, v := range spec.Volumes {
+		if kind == "configmap" && v.ConfigMap != nil && v.ConfigMap.Name == name {
+			refs = append(refs, "volume "+v.Name)
+		}
+		if kind == "secret" && v.Secret != nil && v.Secret.SecretName == name {
+			refs = append(refs, "volume "+v.Name)
+		}
+	}
+	for _, c := range spec.Containers {
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if kind == "configmap" && e.ValueFrom.ConfigMapKeyRef != nil && e.ValueFrom.ConfigMapKeyRef.Name == name {
+				refs = append(refs, fmt.Sprintf("env %s in %s", e.Name, c.Name))
+			}
+			if kind == "secret" && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == name {
+				refs = append(refs, fmt.Sprintf("env %s in %s", e.Name, c.Name))
+			}
+		}
+	}
+	if kind == "secret" {
+		for _, s := range spec.ImagePullSecrets {
+			if s.Name == name {
+				refs = append(refs, "imagePullSecrets")
+			}
+		}
+	}
+	return
+}
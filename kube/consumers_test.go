@@ -0,0 +1,53 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsumed(t *testing.T) {
+	kind, name, err := parseConsumed("cm/app-config")
+	require.NoError(t, err)
+	assert.Equal(t, "configmap", kind)
+	assert.Equal(t, "app-config", name)
+	kind, _, err = parseConsumed("secret/db")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", kind)
+	_, _, err = parseConsumed("rc/web")
+	assert.Error(t, err)
+	_, _, err = parseConsumed("configmap/")
+	assert.Error(t, err)
+}
+
+func TestConsumerRows(t *testing.T) {
+	web := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	web.Name, web.Namespace = "web", "default"
+	spec := &web.Spec.Template.Spec
+	spec.Volumes = []Volume{
+		{Name: "config", VolumeSource: VolumeSource{ConfigMap: &ConfigMapVolumeSource{LocalObjectReference: LocalObjectReference{Name: "app"}}}},
+		{Name: "tls", VolumeSource: VolumeSource{Secret: &SecretVolumeSource{SecretName: "tls"}}},
+	}
+	spec.Containers[0].Env = []EnvVar{
+		{Name: "MODE", ValueFrom: &EnvVarSource{ConfigMapKeyRef: &ConfigMapKeySelector{LocalObjectReference{Name: "app"}, "mode"}}},
+		{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{SecretKeyRef: &SecretKeySelector{LocalObjectReference{Name: "db"}, "password"}}},
+		{Name: "PLAIN", Value: "app"},
+	}
+	spec.ImagePullSecrets = []LocalObjectReference{{Name: "registry"}}
+	worker := testManifestRC(1, "worker:1.0", nil, nil)
+	worker.Name, worker.Namespace = "worker", "default"
+	worker.Spec.Template.Spec.ImagePullSecrets = []LocalObjectReference{{Name: "registry"}}
+	rcs := []ReplicationController{web, worker}
+
+	rows := consumerRows(rcs, "configmap", "app")
+	require.Len(t, rows, 1)
+	assert.Equal(t, ConsumerRow{Namespace: "default", RC: "web", References: []string{"volume config", "env MODE in " + spec.Containers[0].Name}}, rows[0])
+
+	rows = consumerRows(rcs, "secret", "registry")
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"imagePullSecrets"}, rows[1].References)
+
+	assert.Equal(t, []string{"volume tls"}, consumerRows(rcs, "secret", "tls")[0].References)
+	assert.Empty(t, consumerRows(rcs, "configmap", "tls"))
+}
@@ -0,0 +1,137 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/buger/goterm"
+)
+
+// CrashRow is a row of crash report. One row is made for each crashing container.
+type CrashRow struct {
+	// RC owning pod. Empty when pod is not owned by RC.
+	RC string `json:"rc"`
+	// Namespace of pod.
+	Namespace string `json:"namespace"`
+	// Pod name.
+	Pod string `json:"pod"`
+	// Container name.
+	Container string `json:"container"`
+	// Restarts is restart count of container.
+	Restarts int32 `json:"restarts"`
+	// Waiting is current waiting reason like CrashLoopBackOff or ErrImagePull.
+	Waiting string `json:"waiting"`
+	// Reason of last termination like OOMKilled or Error.
+	Reason string `json:"reason"`
+	// ExitCode of last termination.
+	ExitCode int32 `json:"exitCode"`
+	// FinishedAt is time of last termination in RFC3339.
+	FinishedAt string `json:"finishedAt"`
+	// LastCrash is time since last termination like "5m".
+	LastCrash string `json:"lastCrash"`
+}
+
+// waitingReasons are reasons of waiting containers reported as crash
+// even when they have never restarted.
+var waitingReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "RunContainerError"}
+
+// PrintCrashes prints crashing containers grouped per RC, ranked by restarts.
+// All pods are reported when rcname is empty.
+func (t *Tool) PrintCrashes(rcname string) (err error) {
+	var selector Selector
+	if rcname != "" {
+		selector = Selector{"name": rcname}
+	}
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(selector)
+	if err != nil {
+		return
+	}
+	rows := crashRows(rcs, pods)
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	t.PrintContext()
+	if len(rows) == 0 {
+		log(green("no crashing containers."))
+		return
+	}
+	for i := 0; i < len(rows); {
+		group := rows[i].Namespace + "/" + rows[i].RC
+		var restarts int32
+		w := goterm.NewTable(0, 4, 1, ' ', 0)
+		fmt.Fprintf(w, "POD\tCONTAINER\tRESTARTS\tWAITING\tLAST REASON\tEXIT\tFINISHED AT\tLAST CRASH\n")
+		for ; i < len(rows) && rows[i].Namespace+"/"+rows[i].RC == group; i++ {
+			r := rows[i]
+			restarts += r.Restarts
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				r.Pod, r.Container, red("%d", r.Restarts), yellow(orNone(r.Waiting)), red(orNone(r.Reason)),
+				r.ExitCode, orNone(r.FinishedAt), orNone(r.LastCrash),
+			)
+		}
+		log("rc      :", blue(orNone(rows[i-1].RC)), gray("(%s, %d restarts)", rows[i-1].Namespace, restarts))
+		fmt.Fprintln(out, w.String())
+	}
+	return
+}
+
+// crashRows returns crashing containers grouped per RC. Groups and rows in
+// them are ordered by restarts.
+func crashRows(rcs []ReplicationController, pods []Pod) []CrashRow {
+	rows := []CrashRow{}
+	for _, pod := range pods {
+		owner := ""
+		for _, rc := range rcs {
+			if rc.Namespace == pod.Namespace && Selector(rc.Spec.Selector).Matches(pod.Labels) {
+				owner = rc.Name
+				break
+			}
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			row := CrashRow{RC: owner, Namespace: pod.Namespace, Pod: pod.Name, Container: cs.Name, Restarts: cs.RestartCount}
+			if cs.State.Waiting != nil {
+				row.Waiting = cs.State.Waiting.Reason
+			}
+			last := cs.LastTerminationState.Terminated
+			if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+				last = cs.State.Terminated
+			}
+			if last != nil {
+				row.Reason, row.ExitCode = last.Reason, last.ExitCode
+				row.FinishedAt, row.LastCrash = created(last.FinishedAt), age(last.FinishedAt)
+			}
+			if row.Restarts > 0 || contains(row.Waiting, waitingReasons) || (last != nil && last.ExitCode != 0) {
+				rows = append(rows, row)
+			}
+		}
+	}
+	totals := map[string]int32{}
+	for _, r := range rows {
+		totals[r.Namespace+"/"+r.RC] += r.Restarts
+	}
+	sort.Stable(crashRowSorter{rows, totals})
+	return rows
+}
+
+// crashRowSorter orders groups by total restarts, then rows by restarts.
+type crashRowSorter struct {
+	rows   []CrashRow
+	totals map[string]int32
+}
+
+func (s crashRowSorter) Len() int      { return len(s.rows) }
+func (s crashRowSorter) Swap(i, j int) { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s crashRowSorter) Less(i, j int) bool {
+	a, b := s.rows[i], s.rows[j]
+	ga, gb := a.Namespace+"/"+a.RC, b.Namespace+"/"+b.RC
+	switch {
+	case ga == gb:
+		return a.Restarts > b.Restarts
+	case s.totals[ga] != s.totals[gb]:
+		return s.totals[ga] > s.totals[gb]
+	}
+	return ga < gb
+}
@@ -0,0 +1,37 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashRows(t *testing.T) {
+	web := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	web.Namespace = "default"
+	web.Spec.Selector = map[string]string{"name": "web"}
+
+	finished := time.Now().Add(-5 * time.Minute)
+	oom := testPod("web-1", "nginx:1.9.1", ContainerState{Waiting: &ContainerStateWaiting{Reason: "CrashLoopBackOff"}}, 4)
+	oom.Status.ContainerStatuses[0].LastTerminationState.Terminated = &ContainerStateTerminated{
+		ExitCode: 137, Reason: "OOMKilled", FinishedAt: Time{finished},
+	}
+	pull := testPod("web-2", "nginx:1.9.2", ContainerState{Waiting: &ContainerStateWaiting{Reason: "ErrImagePull"}}, 0)
+	flaky := testPod("db-1", "mysql:5.7", ContainerState{Running: &ContainerStateRunning{}}, 6)
+	flaky.Labels = map[string]string{"name": "db"}
+	healthy := testPod("web-3", "nginx:1.9.1", ContainerState{Running: &ContainerStateRunning{}}, 0)
+
+	rows := crashRows([]ReplicationController{web}, []Pod{pull, healthy, oom, flaky})
+	require.Len(t, rows, 3)
+	assert.Equal(t, "db-1", rows[0].Pod)
+	assert.Equal(t, "", rows[0].RC)
+	assert.Equal(t, CrashRow{
+		RC: "web", Namespace: "default", Pod: "web-1", Container: "web", Restarts: 4,
+		Waiting: "CrashLoopBackOff", Reason: "OOMKilled", ExitCode: 137,
+		FinishedAt: created(Time{finished}), LastCrash: "5m",
+	}, rows[1])
+	assert.Equal(t, "web-2", rows[2].Pod)
+	assert.Equal(t, "ErrImagePull", rows[2].Waiting)
+}
@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// printDiff writes colored unified diff between lines of before and after.
+// It returns false when nothing is changed.
+func printDiff(before []string, after []string) (changed bool, err error) {
+	return printFileDiff("current", "new", before, after)
+}
+
+// printFileDiff is printDiff with names of files in header.
+func printFileDiff(from string, to string, before []string, after []string) (changed bool, err error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        withNewline(before),
+		B:        withNewline(after),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	if err != nil || diff == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			log(bold(line))
+		case strings.HasPrefix(line, "@@"):
+			log(cyan(line))
+		case strings.HasPrefix(line, "-"):
+			log(red(line))
+		case strings.HasPrefix(line, "+"):
+			log(green(line))
+		default:
+			log(line)
+		}
+	}
+	return true, nil
+}
+
+func withNewline(lines []string) []string {
+	list := make([]string, len(lines))
+	for i := range lines {
+		list[i] = lines[i] + "\n"
+	}
+	return list
+}
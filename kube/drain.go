@@ -0,0 +1,218 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Annotation keys of pods which are not evicted by drain.
+const (
+	mirrorAnnotation    = "kubernetes.io/config.mirror"
+	createdByAnnotation = "kubernetes.io/created-by"
+)
+
+// drainPod is a pod on draining node.
+type drainPod struct {
+	pod Pod
+	// rc owning pod. Empty when pod is not owned by RC.
+	rc string
+	// skip is reason not to evict pod like mirror pod or DaemonSet.
+	skip string
+}
+
+// Drain cordons node and evicts its pods one by one, waiting for RC of each
+// pod to become available before evicting next one. RCs too small to stay
+// available, like RC of 1 replica, wait for replacement pods instead. Mirror
+// pods and pods of DaemonSet are left on node. Pods not owned by RC are lost
+// so that they are only evicted when deleteUnmanaged is set.
+func (t *Tool) Drain(node string, deleteUnmanaged bool) (err error) {
+	// pods on node belong to any namespace.
+	all := t.kubectl
+	all.Namespace = "all"
+	rcs, err := all.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := all.PodList(nil)
+	if err != nil {
+		return
+	}
+	targets := drainTargets(node, rcs, pods)
+	replaced := map[string]bool{}
+	for _, rc := range rcs {
+		if t.waitsReplacement(rc) {
+			replaced[rc.Namespace+"/"+rc.Name] = true
+		}
+	}
+
+	t.PrintContext()
+	log("node    :", blue(node))
+	unmanaged := []string{}
+	for i, d := range targets {
+		name := d.pod.Namespace + "/" + d.pod.Name
+		switch {
+		case d.skip != "":
+			logf("pod[%03d]: %s %s", i, gray(name), gray("(skip: %s)", d.skip))
+		case d.rc == "":
+			logf("pod[%03d]: %s %s", i, yellow(name), yellow("(not owned by rc)"))
+			unmanaged = append(unmanaged, name)
+		case replaced[d.pod.Namespace+"/"+d.rc]:
+			logf("pod[%03d]: %s %s", i, yellow(name), yellow("(rc %s is unavailable until replaced)", d.rc))
+		case t.podAvailable(d.pod):
+			logf("pod[%03d]: %s %s", i, green(name), gray("(rc %s)", d.rc))
+		default:
+			logf("pod[%03d]: %s %s", i, red(name), gray("(rc %s)", d.rc))
+		}
+	}
+	if len(unmanaged) > 0 && !deleteUnmanaged {
+		err = fmt.Errorf("pods not owned by rc would be lost: %s. Use --delete-unmanaged to evict them", strings.Join(unmanaged, ", "))
+		return
+	}
+	t.confirm("continue?")
+
+	logf("cordoning node %s...", blue(node))
+	if err = t.kubectl.CordonNode(node, true); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%s (node %s is still cordoned)", err, node)
+		}
+	}()
+	deleted := []string{}
+	for _, d := range targets {
+		if d.skip != "" {
+			continue
+		}
+		nt := t.in(d.pod.Namespace)
+		logf("evicting pod %s...", blue(d.pod.Namespace+"/"+d.pod.Name))
+		if err = nt.kubectl.DeletePod(d.pod.Name); err != nil {
+			return
+		}
+		deleted = append(deleted, d.pod.Name)
+		// terminated pods do not count for availability.
+		if d.rc == "" || !podActive(d.pod) {
+			continue
+		}
+		if replaced[d.pod.Namespace+"/"+d.rc] {
+			err = nt.waitReplacement(d.rc, deleted)
+		} else {
+			err = nt.waitRCAvailable(d.rc, deleted)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if t.dryRun {
+		log(yellow("[dry-run] node is not drained"))
+		return
+	}
+	log(green("done draining node"))
+	return
+}
+
+// waitsReplacement is true when RC can not have more available pods than
+// required by waitRCAvailable while one of its pods is evicted.
+func (t *Tool) waitsReplacement(rc ReplicationController) bool {
+	return rc.Spec.Replicas != nil && int(*rc.Spec.Replicas) <= t.requiredPods(rc)
+}
+
+// waitReplacement waits for all replicas of RC to be available excluding
+// deleted pods, for RC which waitRCAvailable never passes.
+func (t *Tool) waitReplacement(name string, deleted []string) (err error) {
+	if t.force {
+		return
+	}
+	if t.dryRun {
+		logf("%s wait until replacement pods of rc %s are available", yellow("[dry-run]"), blue(name))
+		return
+	}
+	for i := 0; i < 10; i++ {
+		rc, err := t.kubectl.RC(name)
+		if err != nil {
+			return err
+		}
+		pods, err := t.kubectl.PodList(rc.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		avail := 0
+		for _, pod := range pods {
+			if !contains(pod.Name, deleted) && t.podAvailable(pod) {
+				avail++
+			}
+		}
+		if avail >= int(*rc.Spec.Replicas) {
+			return nil
+		}
+		log("waiting for replacement of rc", blue(name), gray("(%d/%d available)", avail, *rc.Spec.Replicas))
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("replacement pods of rc %s are not available", name)
+}
+
+// Uncordon marks node schedulable again.
+func (t *Tool) Uncordon(node string) (err error) {
+	t.PrintContext()
+	log("node    :", blue(node))
+	if err = t.kubectl.CordonNode(node, false); err != nil {
+		return
+	}
+	if t.dryRun {
+		log(yellow("[dry-run] node is not uncordoned"))
+		return
+	}
+	log(green("node is schedulable"))
+	return
+}
+
+// in returns copy of tool targeting namespace.
+func (t *Tool) in(namespace string) *Tool {
+	nt := *t
+	nt.kubectl.Namespace = namespace
+	return &nt
+}
+
+// drainTargets returns pods on node with their owning RC, or reason to skip them.
+func drainTargets(node string, rcs []ReplicationController, pods []Pod) []drainPod {
+	targets := []drainPod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node {
+			continue
+		}
+		d := drainPod{pod: pod}
+		for _, rc := range rcs {
+			if rc.Namespace == pod.Namespace && Selector(rc.Spec.Selector).Matches(pod.Labels) {
+				d.rc = rc.Name
+				break
+			}
+		}
+		switch {
+		case pod.Annotations[mirrorAnnotation] != "":
+			// deleting mirror pod does nothing. kubelet manages it.
+			d.skip = "mirror pod"
+		case ownedByDaemonSet(pod):
+			// DaemonSet ignores unschedulable and recreates pod on node.
+			d.skip = "DaemonSet"
+		}
+		targets = append(targets, d)
+	}
+	return targets
+}
+
+// ownedByDaemonSet checks owner references and legacy created-by annotation of pod.
+func ownedByDaemonSet(pod Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return strings.Contains(pod.Annotations[createdByAnnotation], `"kind":"DaemonSet"`)
+}
+
+// podActive is false when pod has terminated.
+func podActive(pod Pod) bool {
+	return pod.Status.Phase != PodSucceeded && pod.Status.Phase != PodFailed
+}
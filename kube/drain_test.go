@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainTargets(t *testing.T) {
+	rc := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	rc.Namespace = "default"
+	rc.Spec.Selector = map[string]string{"name": "web"}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-2", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("fluentd-1", "fluentd:0.12", ContainerState{}, 0),
+		testPod("kube-proxy-1", "kube-proxy:1.3", ContainerState{}, 0),
+		testPod("node-exporter-1", "node-exporter:0.12", ContainerState{}, 0),
+		testPod("debug", "busybox", ContainerState{}, 0),
+	}
+	pods[1].Spec.NodeName = "node-2"
+	pods[2].Labels = nil
+	pods[2].OwnerReferences = []OwnerReference{{Kind: "DaemonSet", Name: "fluentd"}}
+	pods[3].Labels = nil
+	pods[3].Annotations = map[string]string{mirrorAnnotation: "abc"}
+	pods[4].Labels = nil
+	pods[4].Annotations = map[string]string{createdByAnnotation: `{"kind":"SerializedReference","reference":{"kind":"DaemonSet","name":"node-exporter"}}`}
+	pods[5].Labels = nil
+
+	targets := drainTargets("node-1", []ReplicationController{rc}, pods)
+	require.Len(t, targets, 5)
+	assert.Equal(t, "web-1", targets[0].pod.Name)
+	assert.Equal(t, "web", targets[0].rc)
+	assert.Empty(t, targets[0].skip)
+	assert.Equal(t, "DaemonSet", targets[1].skip)
+	assert.Equal(t, "mirror pod", targets[2].skip)
+	assert.Equal(t, "DaemonSet", targets[3].skip)
+	assert.Empty(t, targets[4].rc)
+	assert.Empty(t, targets[4].skip)
+}
+
+func TestWaitsReplacement(t *testing.T) {
+	tool := &Tool{}
+	assert.True(t, tool.waitsReplacement(testManifestRC(1, "nginx:1.9.1", nil, nil)))
+	assert.False(t, tool.waitsReplacement(testManifestRC(2, "nginx:1.9.1", nil, nil)))
+	tool.SetMinimumStable(0.8)
+	assert.True(t, tool.waitsReplacement(testManifestRC(1, "nginx:1.9.1", nil, nil)))
+	assert.False(t, tool.waitsReplacement(testManifestRC(3, "nginx:1.9.1", nil, nil)))
+}
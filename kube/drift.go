@@ -0,0 +1,112 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buger/goterm"
+)
+
+// Kinds of drift.
+const (
+	DriftImage    = "image"
+	DriftReplicas = "replicas"
+)
+
+// DriftRow is a difference between RC and its running state.
+type DriftRow struct {
+	// Kind is "image" for pod running images different from RC,
+	// or "replicas" for RC whose observed replicas differ from desired.
+	Kind string `json:"kind"`
+	// Namespace of RC.
+	Namespace string `json:"namespace"`
+	// RC name.
+	RC string `json:"rc"`
+	// Pod name of image drift.
+	Pod string `json:"pod"`
+	// Container name of image drift. Empty when containers of pod differ from RC.
+	Container string `json:"container"`
+	// Expected is image of RC template or desired replicas.
+	Expected string `json:"expected"`
+	// Actual is image of running pod or observed replicas.
+	Actual string `json:"actual"`
+}
+
+// Drift prints pods running images different from their RCs, and RCs whose
+// observed replicas differ from desired. It returns true when drift exists.
+func (t *Tool) Drift() (found bool, err error) {
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows := driftRows(rcs, pods)
+	found = len(rows) > 0
+	if t.output != OutputTable && t.output != OutputWide {
+		err = writeRows(t.output, rows)
+		return
+	}
+	t.PrintContext()
+	if !found {
+		log(green("no drift found."))
+		return
+	}
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NAMESPACE\tRC\tKIND\tPOD\tCONTAINER\tEXPECTED\tACTUAL\n")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Namespace, r.RC, r.Kind, orNone(r.Pod), orNone(r.Container), green(r.Expected), red(r.Actual),
+		)
+	}
+	fmt.Fprintln(out, w.String())
+	return
+}
+
+// driftRows compares RCs with their pods in the same way as FixVersion.
+func driftRows(rcs []ReplicationController, pods []Pod) []DriftRow {
+	rows := []DriftRow{}
+	for _, rc := range rcs {
+		if rc.Spec.Template == nil {
+			continue
+		}
+		if desired := replicas(rc); desired != "" && desired != fmt.Sprint(rc.Status.Replicas) {
+			rows = append(rows, DriftRow{
+				Kind:      DriftReplicas,
+				Namespace: rc.Namespace,
+				RC:        rc.Name,
+				Expected:  desired,
+				Actual:    fmt.Sprint(rc.Status.Replicas),
+			})
+		}
+		cs := templateContainers(rc)
+		for _, pod := range rcPods(rc, pods) {
+			if !outdated(rc, pod) {
+				continue
+			}
+			row := DriftRow{Kind: DriftImage, Namespace: rc.Namespace, RC: rc.Name, Pod: pod.Name}
+			if len(pod.Spec.Containers) != len(cs) {
+				row.Expected, row.Actual = containerImageList(cs), containerImageList(pod.Spec.Containers)
+				rows = append(rows, row)
+				continue
+			}
+			for i, c := range pod.Spec.Containers {
+				if c.Image != cs[i].Image {
+					row.Container, row.Expected, row.Actual = c.Name, cs[i].Image, c.Image
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+func containerImageList(cs []Container) string {
+	images := make([]string, len(cs))
+	for i := range cs {
+		images[i] = cs[i].Image
+	}
+	return strings.Join(images, ",")
+}
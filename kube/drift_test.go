@@ -0,0 +1,45 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftRows(t *testing.T) {
+	rc := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	rc.Namespace = "default"
+	rc.Spec.Selector = map[string]string{"name": "web"}
+	rc.Status.Replicas = 2
+
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-2", "nginx:1.9.0", ContainerState{}, 0),
+	}
+	rows := driftRows([]ReplicationController{rc}, pods)
+	require.Len(t, rows, 1)
+	assert.Equal(t, DriftRow{
+		Kind: DriftImage, Namespace: "default", RC: "web", Pod: "web-2",
+		Container: "web", Expected: "nginx:1.9.1", Actual: "nginx:1.9.0",
+	}, rows[0])
+
+	rc.Status.Replicas = 1
+	pods[1].Spec.Containers = append(pods[1].Spec.Containers, Container{Name: "proxy", Image: "envoy"})
+	rows = driftRows([]ReplicationController{rc}, pods)
+	require.Len(t, rows, 2)
+	assert.Equal(t, DriftReplicas, rows[0].Kind)
+	assert.Equal(t, "2", rows[0].Expected)
+	assert.Equal(t, "1", rows[0].Actual)
+	assert.Equal(t, "", rows[1].Container)
+	assert.Equal(t, "nginx:1.9.0,envoy", rows[1].Actual)
+}
+
+func TestDriftRowsInSync(t *testing.T) {
+	rc := testManifestRC(1, "nginx:1.9.1", nil, nil)
+	rc.Namespace = "default"
+	rc.Spec.Selector = map[string]string{"name": "web"}
+	rc.Status.Replicas = 1
+	pods := []Pod{testPod("web-1", "nginx:1.9.1", ContainerState{}, 0)}
+	assert.Empty(t, driftRows([]ReplicationController{rc}, pods))
+}
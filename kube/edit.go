@@ -0,0 +1,253 @@
+package kube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpdateEnv sets or removes environment variables of RC container.
+// Each env is KEY=VAL to set or KEY- to remove.
+func (t *Tool) UpdateEnv(name string, container string, envs []string) (err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	c, err := pickContainer(rc, container)
+	if err != nil {
+		return
+	}
+	sets, unsets, err := parseAssignments(envs)
+	if err != nil {
+		return
+	}
+	newEnv := applyEnv(c.Env, sets, unsets)
+
+	t.PrintContext()
+	log("RC       :", green(name))
+	log("Container:", green(c.Name))
+	changed, err := printDiff(formatEnv(c.Env), formatEnv(newEnv))
+	if err != nil {
+		return
+	}
+	if !changed {
+		log(green("nothing to change."))
+		return
+	}
+	t.confirm("continue?")
+
+	patch := []map[string]interface{}{}
+	for _, env := range c.Env {
+		if contains(env.Name, unsets) {
+			patch = append(patch, map[string]interface{}{"name": env.Name, "$patch": "delete"})
+		}
+	}
+	for _, k := range sortedKeys(sets) {
+		e := map[string]interface{}{"name": k, "value": sets[k]}
+		// drop valueFrom since value can not be used with it.
+		for _, env := range c.Env {
+			if env.Name == k && env.ValueFrom != nil {
+				e["valueFrom"] = nil
+			}
+		}
+		patch = append(patch, e)
+	}
+	err = t.patchRC(rc.Name, containerPatch(c.Name, "env", patch))
+	return
+}
+
+// UpdateResources sets or removes resource requests and limits of RC container.
+// Each resource is NAME=QUANTITY to set or NAME- to remove.
+func (t *Tool) UpdateResources(name string, container string, requests []string, limits []string) (err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	c, err := pickContainer(rc, container)
+	if err != nil {
+		return
+	}
+	reqSets, reqUnsets, err := parseAssignments(requests)
+	if err != nil {
+		return
+	}
+	limSets, limUnsets, err := parseAssignments(limits)
+	if err != nil {
+		return
+	}
+	newRes := ResourceRequirements{
+		Requests: applyResources(c.Resources.Requests, reqSets, reqUnsets),
+		Limits:   applyResources(c.Resources.Limits, limSets, limUnsets),
+	}
+
+	t.PrintContext()
+	log("RC       :", green(name))
+	log("Container:", green(c.Name))
+	changed, err := printDiff(formatResources(c.Resources), formatResources(newRes))
+	if err != nil {
+		return
+	}
+	if !changed {
+		log(green("nothing to change."))
+		return
+	}
+	t.confirm("continue?")
+
+	patch := map[string]interface{}{}
+	if p := resourcePatch(reqSets, reqUnsets); len(p) > 0 {
+		patch["requests"] = p
+	}
+	if p := resourcePatch(limSets, limUnsets); len(p) > 0 {
+		patch["limits"] = p
+	}
+	err = t.patchRC(rc.Name, containerPatch(c.Name, "resources", patch))
+	return
+}
+
+// parseAssignments parses list of KEY=VAL and KEY- into sets and unsets.
+// Values are taken as is, so that they may contain commas.
+func parseAssignments(args []string) (sets map[string]string, unsets []string, err error) {
+	sets = map[string]string{}
+	for _, a := range args {
+		if eq := strings.IndexByte(a, '='); eq > 0 {
+			sets[a[:eq]] = a[eq+1:]
+			continue
+		}
+		if strings.HasSuffix(a, "-") && len(a) > 1 {
+			unsets = append(unsets, strings.TrimSuffix(a, "-"))
+			continue
+		}
+		if a != "" {
+			return nil, nil, fmt.Errorf("invalid argument %q: must be KEY=VAL or KEY-", a)
+		}
+	}
+	if len(sets) == 0 && len(unsets) == 0 {
+		err = errors.New("no change specified")
+	}
+	return
+}
+
+// applyEnv returns env list with sets and unsets applied.
+func applyEnv(envs []EnvVar, sets map[string]string, unsets []string) []EnvVar {
+	list := []EnvVar{}
+	done := map[string]bool{}
+	for _, env := range envs {
+		if contains(env.Name, unsets) {
+			continue
+		}
+		if v, ok := sets[env.Name]; ok {
+			env = EnvVar{Name: env.Name, Value: v}
+			done[env.Name] = true
+		}
+		list = append(list, env)
+	}
+	for _, k := range sortedKeys(sets) {
+		if !done[k] {
+			list = append(list, EnvVar{Name: k, Value: sets[k]})
+		}
+	}
+	return list
+}
+
+// applyResources returns copy of resource list with sets and unsets applied.
+func applyResources(rl ResourceList, sets map[string]string, unsets []string) ResourceList {
+	list := ResourceList{}
+	for k, v := range rl {
+		if !contains(string(k), unsets) {
+			list[k] = v
+		}
+	}
+	for k, v := range sets {
+		list[ResourceName(k)] = v
+	}
+	return list
+}
+
+func resourcePatch(sets map[string]string, unsets []string) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for _, k := range unsets {
+		patch[k] = nil
+	}
+	for k, v := range sets {
+		patch[k] = v
+	}
+	return patch
+}
+
+// containerPatch builds strategic merge patch of single container field in RC template.
+func containerPatch(container string, field string, value interface{}) string {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []map[string]interface{}{
+						{"name": container, field: value},
+					},
+				},
+			},
+		},
+	}
+	b, _ := json.Marshal(patch)
+	return string(b)
+}
+
+// formatEnv returns env as lines of KEY=VAL.
+func formatEnv(envs []EnvVar) []string {
+	lines := make([]string, len(envs))
+	for i, env := range envs {
+		lines[i] = env.Name + "=" + envValue(env)
+	}
+	return lines
+}
+
+// envValue describes value or source of env.
+func envValue(env EnvVar) string {
+	if env.ValueFrom == nil {
+		return env.Value
+	}
+	switch src := env.ValueFrom; {
+	case src.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("<configmap %s/%s>", src.ConfigMapKeyRef.Name, src.ConfigMapKeyRef.Key)
+	case src.SecretKeyRef != nil:
+		return fmt.Sprintf("<secret %s/%s>", src.SecretKeyRef.Name, src.SecretKeyRef.Key)
+	case src.FieldRef != nil:
+		return fmt.Sprintf("<field %s>", src.FieldRef.FieldPath)
+	}
+	return ""
+}
+
+// formatResources returns resources as lines of requests.NAME=QUANTITY.
+func formatResources(res ResourceRequirements) []string {
+	lines := []string{}
+	for _, k := range sortedResourceNames(res.Requests) {
+		lines = append(lines, "requests."+string(k)+"="+res.Requests[k])
+	}
+	for _, k := range sortedResourceNames(res.Limits) {
+		lines = append(lines, "limits."+string(k)+"="+res.Limits[k])
+	}
+	return lines
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedResourceNames(rl ResourceList) []ResourceName {
+	keys := make([]string, 0, len(rl))
+	for k := range rl {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+	names := make([]ResourceName, len(keys))
+	for i := range keys {
+		names[i] = ResourceName(keys[i])
+	}
+	return names
+}
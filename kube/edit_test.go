@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAssignments(t *testing.T) {
+	sets, unsets, err := parseAssignments([]string{"A=1", "B=x=y", "C-", "D=", "HOSTS=a,b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1", "B": "x=y", "D": "", "HOSTS": "a,b"}, sets)
+	assert.Equal(t, []string{"C"}, unsets)
+
+	_, _, err = parseAssignments([]string{"A"})
+	assert.Error(t, err)
+	_, _, err = parseAssignments(nil)
+	assert.Error(t, err)
+}
+
+func TestApplyEnv(t *testing.T) {
+	envs := []EnvVar{
+		{Name: "A", Value: "1"},
+		{Name: "B", ValueFrom: &EnvVarSource{SecretKeyRef: &SecretKeySelector{LocalObjectReference{"s"}, "k"}}},
+		{Name: "C", Value: "3"},
+	}
+	newEnv := applyEnv(envs, map[string]string{"B": "2", "D": "4"}, []string{"C"})
+	assert.Equal(t, []string{"A=1", "B=2", "D=4"}, formatEnv(newEnv))
+	assert.Equal(t, []string{"A=1", "B=<secret s/k>", "C=3"}, formatEnv(envs))
+}
+
+func TestApplyResources(t *testing.T) {
+	res := ResourceRequirements{
+		Requests: ResourceList{"cpu": "100m", "memory": "256Mi"},
+	}
+	newRes := ResourceRequirements{
+		Requests: applyResources(res.Requests, map[string]string{"cpu": "200m"}, []string{"memory"}),
+		Limits:   applyResources(res.Limits, map[string]string{"memory": "1Gi"}, nil),
+	}
+	assert.Equal(t, []string{"requests.cpu=200m", "limits.memory=1Gi"}, formatResources(newRes))
+	assert.Equal(t, "256Mi", res.Requests["memory"])
+}
+
+func TestContainerPatch(t *testing.T) {
+	patch := containerPatch("web", "resources", map[string]interface{}{
+		"limits": resourcePatch(map[string]string{"memory": "1Gi"}, []string{"cpu"}),
+	})
+	assert.Equal(t, `{"spec":{"template":{"spec":{"containers":[{"name":"web","resources":{"limits":{"cpu":null,"memory":"1Gi"}}}]}}}}`, patch)
+}
@@ -0,0 +1,139 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Events prints events of RC and its pods in order of time.
+// New events are streamed when follow is set.
+func (t *Tool) Events(name string, follow bool) (err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	t.PrintContext()
+	log("rc      :", blue(name))
+	tracker := newEventTracker(nil)
+	if err = t.printNewEvents(rc, tracker, false); err != nil {
+		return
+	}
+	if len(tracker.seen) == 0 {
+		log(gray("no events."))
+	}
+	if !follow {
+		return
+	}
+	events, stop := t.kubectl.Watch("event", nil)
+	defer stop()
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				// watch ended. fall back to polling.
+				events = nil
+			}
+		case <-ticker.C:
+		}
+		if err = t.printNewEvents(rc, tracker, false); err != nil {
+			return
+		}
+	}
+}
+
+// printNewEvents prints events of RC and its current pods not printed yet.
+func (t *Tool) printNewEvents(rc ReplicationController, tracker *eventTracker, warningOnly bool) (err error) {
+	pods, err := t.kubectl.PodList(rc.Spec.Selector)
+	if err != nil {
+		return
+	}
+	events, err := t.kubectl.EventList()
+	if err != nil {
+		return
+	}
+	for _, e := range tracker.next(rcEvents(rc, pods, events)) {
+		if !warningOnly || e.Type == EventTypeWarning {
+			log(formatEvent(e))
+		}
+	}
+	return
+}
+
+// eventTracker picks events not seen yet.
+// Events repeated with increased count are picked again.
+type eventTracker struct {
+	seen map[string]bool
+}
+
+// newEventTracker tracks events except existing ones. Existing events are
+// given from server rather than compared with local clock, which may be skewed
+// from timestamps of server.
+func newEventTracker(existing []Event) *eventTracker {
+	et := &eventTracker{seen: map[string]bool{}}
+	et.next(existing)
+	return et
+}
+
+func (et *eventTracker) next(events []Event) (list []Event) {
+	for _, e := range events {
+		key := fmt.Sprintf("%s/%s/%d", e.Namespace, e.Name, e.Count)
+		if et.seen[key] {
+			continue
+		}
+		et.seen[key] = true
+		list = append(list, e)
+	}
+	return
+}
+
+// formatEvent formats event as a line of timeline.
+func formatEvent(e Event) string {
+	typ := green(e.Type)
+	if e.Type == EventTypeWarning {
+		typ = red(e.Type)
+	}
+	count := ""
+	if e.Count > 1 {
+		count = gray(" (x%d)", e.Count)
+	}
+	source := e.Source.Component
+	if e.Source.Host != "" {
+		source += "/" + e.Source.Host
+	}
+	if source != "" {
+		source = gray(" [%s]", source)
+	}
+	ts := ""
+	if !e.LastTimestamp.IsZero() {
+		ts = e.LastTimestamp.Local().Format("01-02 15:04:05")
+	}
+	return fmt.Sprintf("%s %s %s %s%s%s %s",
+		gray(ts), typ, cyan("%s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Name),
+		bold(e.Reason), count, source, e.Message)
+}
+
+// rcEvents returns events of RC and its pods in order of time.
+func rcEvents(rc ReplicationController, pods []Pod, events []Event) (list []Event) {
+	names := map[string]bool{"ReplicationController/" + rc.Name: true}
+	for _, pod := range pods {
+		names["Pod/"+pod.Name] = true
+	}
+	for _, e := range events {
+		if e.InvolvedObject.Namespace == rc.Namespace && names[e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name] {
+			list = append(list, e)
+		}
+	}
+	sort.Stable(eventsByTime(list))
+	return
+}
+
+type eventsByTime []Event
+
+func (s eventsByTime) Len() int      { return len(s) }
+func (s eventsByTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s eventsByTime) Less(i, j int) bool {
+	return s[i].LastTimestamp.Time.Before(s[j].LastTimestamp.Time)
+}
@@ -0,0 +1,60 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRCEvents(t *testing.T) {
+	rc := ReplicationController{}
+	rc.Name, rc.Namespace = "web", "default"
+	pods := []Pod{testPod("web-1", "nginx:1.9.1", ContainerState{}, 0)}
+	event := func(kind string, name string, ts time.Time) Event {
+		e := Event{Reason: kind + "/" + name}
+		e.InvolvedObject = ObjectReference{Kind: kind, Namespace: "default", Name: name}
+		e.LastTimestamp = Time{ts}
+		return e
+	}
+	now := time.Now()
+	events := []Event{
+		event("Pod", "web-1", now),
+		event("Pod", "db-1", now),
+		event("ReplicationController", "web", now.Add(-time.Minute)),
+		event("Pod", "web", now),
+	}
+	list := rcEvents(rc, pods, events)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "ReplicationController/web", list[0].Reason)
+		assert.Equal(t, "Pod/web-1", list[1].Reason)
+	}
+}
+
+func TestEventTracker(t *testing.T) {
+	now := time.Now()
+	e := Event{Count: 1}
+	e.Name, e.Namespace = "web-1.abc", "default"
+	e.LastTimestamp = Time{now}
+	old := Event{Count: 1}
+	old.Name = "web-0.abc"
+	old.LastTimestamp = Time{now.Add(-time.Hour)}
+
+	tracker := newEventTracker([]Event{old})
+	assert.Equal(t, []Event{e}, tracker.next([]Event{old, e}))
+	assert.Empty(t, tracker.next([]Event{old, e}))
+	e.Count = 2
+	assert.Equal(t, []Event{e}, tracker.next([]Event{e}))
+}
+
+func TestFormatEvent(t *testing.T) {
+	e := Event{Type: EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting", Count: 3}
+	e.InvolvedObject = ObjectReference{Kind: "Pod", Name: "web-1"}
+	e.Source = EventSource{Component: "kubelet", Host: "node-1"}
+	line := formatEvent(e)
+	assert.Contains(t, line, red(EventTypeWarning))
+	assert.Contains(t, line, cyan("Pod/web-1"))
+	assert.Contains(t, line, gray(" (x3)"))
+	assert.Contains(t, line, gray(" [kubelet/node-1]"))
+	assert.Contains(t, line, "Back-off restarting")
+}
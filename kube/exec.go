@@ -0,0 +1,128 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExecRow is a result of command run in a pod.
+type ExecRow struct {
+	// Pod name.
+	Pod string `json:"pod"`
+	// Container name.
+	Container string `json:"container"`
+	// ExitCode of command. -1 when kubectl failed to run it.
+	ExitCode int `json:"exitCode"`
+	// Stdout of command.
+	Stdout string `json:"stdout"`
+	// Stderr of command, or error of kubectl.
+	Stderr string `json:"stderr"`
+}
+
+// Exec runs command in container of all pods of RC, parallel pods at once.
+// Pods with identical output are grouped. failed is true when any pod failed.
+func (t *Tool) Exec(name string, container string, parallel int, command []string) (failed bool, err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	c, err := pickContainer(rc, container)
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(rc.Spec.Selector)
+	if err != nil {
+		return
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	rows := make([]ExecRow, len(pods))
+	sem := make(chan bool, parallel)
+	wg := sync.WaitGroup{}
+	for i := range pods {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- true
+			defer func() { <-sem }()
+			row := ExecRow{Pod: pods[i].Name, Container: c.Name}
+			stdout, stderr, code, err := t.kubectl.ExecPod(row.Pod, row.Container, command)
+			row.Stdout, row.Stderr, row.ExitCode = string(stdout), string(stderr), code
+			if err != nil {
+				row.Stderr, row.ExitCode = err.Error(), -1
+			}
+			rows[i] = row
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range rows {
+		failed = failed || r.ExitCode != 0
+	}
+
+	if t.output != OutputTable && t.output != OutputWide {
+		err = writeRows(t.output, rows)
+		return
+	}
+	if t.dryRun {
+		return
+	}
+	for _, g := range groupExecRows(rows) {
+		names := make([]string, len(g))
+		for i := range g {
+			names[i] = g[i].Pod
+		}
+		status := green("exit %d", g[0].ExitCode)
+		if g[0].ExitCode != 0 {
+			status = red("exit %d", g[0].ExitCode)
+		}
+		log(bold("== %d pod(s)", len(g)), status, blue(strings.Join(names, ", ")))
+		if g[0].Stdout != "" {
+			fmt.Fprint(out, terminated(g[0].Stdout))
+		}
+		if g[0].Stderr != "" {
+			fmt.Fprint(out, red("%s", terminated(g[0].Stderr)))
+		}
+	}
+	return
+}
+
+// groupExecRows collapses rows with identical output and exit code.
+// Failed groups come first, then larger groups.
+func groupExecRows(rows []ExecRow) (groups [][]ExecRow) {
+	index := map[string]int{}
+	for _, r := range rows {
+		key := fmt.Sprintf("%d\x00%s\x00%s", r.ExitCode, r.Stdout, r.Stderr)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], r)
+	}
+	sort.Stable(execGroupSorter(groups))
+	return
+}
+
+type execGroupSorter [][]ExecRow
+
+func (s execGroupSorter) Len() int      { return len(s) }
+func (s execGroupSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s execGroupSorter) Less(i, j int) bool {
+	if fi, fj := s[i][0].ExitCode != 0, s[j][0].ExitCode != 0; fi != fj {
+		return fi
+	}
+	return len(s[i]) > len(s[j])
+}
+
+// terminated ensures text ends with newline.
+func terminated(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,23 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupExecRows(t *testing.T) {
+	rows := []ExecRow{
+		{Pod: "web-1", Stdout: "ok\n"},
+		{Pod: "web-2", Stdout: "ok\n"},
+		{Pod: "web-3", Stdout: "ok\n", ExitCode: 1},
+		{Pod: "web-4", Stdout: "ok\n"},
+		{Pod: "web-5", Stdout: "ng\n"},
+	}
+	groups := groupExecRows(rows)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "web-3", groups[0][0].Pod)
+	assert.Len(t, groups[1], 3)
+	assert.Equal(t, "web-5", groups[2][0].Pod)
+}
@@ -0,0 +1,137 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abema/kubetool/registry"
+)
+
+// Sort keys of ListFilter.
+const (
+	SortByName     = "name"
+	SortByRestarts = "restarts"
+	SortByAge      = "age"
+	SortByNode     = "node"
+	SortByVersion  = "version"
+)
+
+// ListFilter narrows and sorts rows of rc and pod listings.
+// Zero value keeps all rows in API order.
+type ListFilter struct {
+	// SortBy is one of name, restarts, age, node or version.
+	SortBy string
+	// Status of container. One of Running, Waiting or Terminated.
+	Status string
+	// Node name of pod.
+	Node string
+	// Image is a part of image name.
+	Image string
+	// Version is exact tag of image.
+	Version string
+	// NotReady keeps only pods not ready, or RCs which lack ready pods.
+	NotReady bool
+	// MinRestarts keeps only containers restarted at least this times. 0 disables.
+	MinRestarts int
+}
+
+func (f ListFilter) matchImage(image string, version string) bool {
+	if f.Image != "" && !strings.Contains(image, f.Image) {
+		return false
+	}
+	return f.Version == "" || f.Version == version
+}
+
+// pods filters and sorts pod rows.
+func (f ListFilter) pods(rows []PodRow) []PodRow {
+	list := []PodRow{}
+	for _, r := range rows {
+		switch {
+		case !f.matchImage(r.Image, r.Version):
+		case f.Status != "" && !strings.EqualFold(f.Status, r.Status):
+		case f.Node != "" && f.Node != r.Node && f.Node != r.NodeIP:
+		case f.NotReady && r.Ready:
+		case int(r.Restarts) < f.MinRestarts:
+		default:
+			list = append(list, r)
+		}
+	}
+	var less func(a, b PodRow) bool
+	switch f.SortBy {
+	case SortByName:
+		less = func(a, b PodRow) bool { return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name }
+	case SortByRestarts:
+		less = func(a, b PodRow) bool { return a.Restarts > b.Restarts }
+	case SortByAge:
+		less = func(a, b PodRow) bool { return a.Created > b.Created }
+	case SortByNode:
+		less = func(a, b PodRow) bool { return a.Node < b.Node }
+	case SortByVersion:
+		less = func(a, b PodRow) bool { return newerVersion(a.Version, b.Version) }
+	default:
+		return list
+	}
+	sort.Stable(podRowSorter{list, less})
+	return list
+}
+
+// rcs filters and sorts RC rows.
+func (f ListFilter) rcs(rows []RCRow) []RCRow {
+	list := []RCRow{}
+	for _, r := range rows {
+		if f.matchImage(r.Image, r.Version) && (!f.NotReady || r.Ready < int(r.Replicas)) {
+			list = append(list, r)
+		}
+	}
+	var less func(a, b RCRow) bool
+	switch f.SortBy {
+	case SortByName:
+		less = func(a, b RCRow) bool { return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name }
+	case SortByAge:
+		less = func(a, b RCRow) bool { return a.Created > b.Created }
+	case SortByVersion:
+		less = func(a, b RCRow) bool { return newerVersion(a.Version, b.Version) }
+	default:
+		return list
+	}
+	sort.Stable(rcRowSorter{list, less})
+	return list
+}
+
+// validate checks sort key for listing of kind.
+func (f ListFilter) validate(kind string) error {
+	keys := []string{"", SortByName, SortByAge, SortByVersion}
+	if kind == "pod" {
+		keys = append(keys, SortByRestarts, SortByNode)
+	}
+	if !contains(f.SortBy, keys) {
+		return fmt.Errorf("can not sort %s by %s", kind, f.SortBy)
+	}
+	return nil
+}
+
+// newerVersion compares tags in semver aware order.
+func newerVersion(a string, b string) bool {
+	tags := []string{b, a}
+	registry.SortTags(tags)
+	return tags[0] == a && a != b
+}
+
+type podRowSorter struct {
+	rows []PodRow
+	less func(a, b PodRow) bool
+}
+
+func (s podRowSorter) Len() int           { return len(s.rows) }
+func (s podRowSorter) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s podRowSorter) Less(i, j int) bool { return s.less(s.rows[i], s.rows[j]) }
+
+type rcRowSorter struct {
+	rows []RCRow
+	less func(a, b RCRow) bool
+}
+
+func (s rcRowSorter) Len() int           { return len(s.rows) }
+func (s rcRowSorter) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s rcRowSorter) Less(i, j int) bool { return s.less(s.rows[i], s.rows[j]) }
@@ -0,0 +1,65 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPodRows() []PodRow {
+	return []PodRow{
+		{Name: "web-1", Namespace: "default", Status: "Running", Ready: true, Restarts: 0, Node: "node-1", NodeIP: "10.0.0.1", Image: "abema/web", Version: "1.9.1", Created: "2016-01-02T00:00:00Z"},
+		{Name: "web-2", Namespace: "default", Status: "Waiting", Ready: false, Restarts: 5, Node: "node-2", NodeIP: "10.0.0.2", Image: "abema/web", Version: "1.10.0", Created: "2016-01-03T00:00:00Z"},
+		{Name: "db-1", Namespace: "infra", Status: "Running", Ready: true, Restarts: 1, Node: "node-1", NodeIP: "10.0.0.1", Image: "mysql", Version: "5.7", Created: "2016-01-01T00:00:00Z"},
+	}
+}
+
+func podNames(rows []PodRow) (names []string) {
+	for _, r := range rows {
+		names = append(names, r.Name)
+	}
+	return
+}
+
+func TestFilterPods(t *testing.T) {
+	rows := testPodRows()
+	cases := []struct {
+		filter   ListFilter
+		expected []string
+	}{
+		{ListFilter{}, []string{"web-1", "web-2", "db-1"}},
+		{ListFilter{Status: "running"}, []string{"web-1", "db-1"}},
+		{ListFilter{Node: "node-2"}, []string{"web-2"}},
+		{ListFilter{Node: "10.0.0.1"}, []string{"web-1", "db-1"}},
+		{ListFilter{Image: "web"}, []string{"web-1", "web-2"}},
+		{ListFilter{Version: "5.7"}, []string{"db-1"}},
+		{ListFilter{NotReady: true}, []string{"web-2"}},
+		{ListFilter{MinRestarts: 1}, []string{"web-2", "db-1"}},
+		{ListFilter{MinRestarts: 2}, []string{"web-2"}},
+		{ListFilter{SortBy: SortByName}, []string{"web-1", "web-2", "db-1"}},
+		{ListFilter{SortBy: SortByRestarts}, []string{"web-2", "db-1", "web-1"}},
+		{ListFilter{SortBy: SortByAge}, []string{"web-2", "web-1", "db-1"}},
+		{ListFilter{SortBy: SortByNode}, []string{"web-1", "db-1", "web-2"}},
+		{ListFilter{SortBy: SortByVersion, Image: "web"}, []string{"web-2", "web-1"}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, podNames(c.filter.pods(rows)), "%+v", c.filter)
+	}
+}
+
+func TestFilterRCs(t *testing.T) {
+	rows := []RCRow{
+		{Name: "web", Namespace: "default", Replicas: 3, Ready: 2, Image: "abema/web", Version: "1.9.1"},
+		{Name: "db", Namespace: "default", Replicas: 1, Ready: 1, Image: "mysql", Version: "5.7"},
+	}
+	assert.Len(t, ListFilter{NotReady: true}.rcs(rows), 1)
+	assert.Equal(t, "db", ListFilter{SortBy: SortByName}.rcs(rows)[0].Name)
+	assert.Equal(t, "web", ListFilter{Image: "abema"}.rcs(rows)[0].Name)
+}
+
+func TestFilterValidate(t *testing.T) {
+	assert.NoError(t, ListFilter{SortBy: SortByRestarts}.validate("pod"))
+	assert.NoError(t, ListFilter{SortBy: SortByVersion}.validate("rc"))
+	assert.Error(t, ListFilter{SortBy: SortByNode}.validate("rc"))
+	assert.Error(t, ListFilter{SortBy: "size"}.validate("pod"))
+}
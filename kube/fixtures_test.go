@@ -0,0 +1,36 @@
+package kube
+
+// Fixtures shared by tests. Objects are in default namespace unless changed
+// by tests.
+
+// testRC returns RC in default namespace selecting pods labeled name=<name>.
+func testRC(name string, replicas int32, image string) ReplicationController {
+	rc := testManifestRC(replicas, image, nil, nil)
+	rc.Name, rc.Namespace = name, "default"
+	rc.Spec.Selector = map[string]string{"name": name}
+	return rc
+}
+
+func testService(name string, selector map[string]string, target IntOrString) Service {
+	svc := Service{}
+	svc.Name = name
+	svc.Namespace = "default"
+	svc.Spec.Type = ServiceTypeClusterIP
+	svc.Spec.Selector = selector
+	svc.Spec.Ports = []ServicePort{{Port: 80, TargetPort: target}}
+	return svc
+}
+
+func testNode(name string, zone string, ready ConditionStatus) Node {
+	node := Node{}
+	node.Name = name
+	node.Labels = map[string]string{zoneLabel: zone}
+	node.Status.Conditions = []NodeCondition{{Type: NodeReady, Status: ready}}
+	return node
+}
+
+func testSecret(name string, typ SecretType, data map[string][]byte) Secret {
+	s := Secret{Type: typ, Data: data}
+	s.Name, s.Namespace = name, "default"
+	return s
+}
@@ -0,0 +1,184 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/buger/goterm"
+)
+
+// ImageRow is a row of image inventory. One row is made for each image and version.
+type ImageRow struct {
+	// Image name without tag.
+	Image string `json:"image"`
+	// Version is tag of image, or digest when image is pinned without tag.
+	Version string `json:"version"`
+	// Pods is number of pods running the image.
+	Pods int `json:"pods"`
+	// Digests of image which containers are actually running.
+	Digests []string `json:"digests"`
+	// Nodes which pods are running on.
+	Nodes []string `json:"nodes"`
+	// Namespaces of pods and RCs.
+	Namespaces []string `json:"namespaces"`
+	// RCs whose template has the image, or which own pods running it.
+	RCs []string `json:"rcs"`
+	// MultipleTags is true when pods run more than one version of the image.
+	MultipleTags bool `json:"multipleTags"`
+}
+
+// PrintImages prints inventory of images in RC templates and running pods.
+// image filters rows by part of image name.
+func (t *Tool) PrintImages(image string) (err error) {
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows, filter := []ImageRow{}, ListFilter{Image: image}
+	for _, r := range imageRows(rcs, pods) {
+		if filter.matchImage(r.Image, r.Version) {
+			rows = append(rows, r)
+		}
+	}
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	if t.output == OutputWide {
+		fmt.Fprintf(w, "IMAGE\tVERSION\tPODS\tNODES\tNAMESPACES\tRCS\tDIGESTS\n")
+	} else {
+		fmt.Fprintf(w, "IMAGE\tVERSION\tPODS\tNODES\tNAMESPACES\tRCS\n")
+	}
+	for _, r := range rows {
+		if t.output == OutputWide {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+				r.Image, r.Version, r.Pods, joinOrNone(r.Nodes), joinOrNone(r.Namespaces), joinOrNone(r.RCs), joinOrNone(shortDigests(r.Digests)),
+			)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+			r.Image, r.Version, r.Pods, len(r.Nodes), joinOrNone(r.Namespaces), joinOrNone(r.RCs),
+		)
+	}
+	// highlight repos running more than one tag.
+	lines, multi := strings.Split(w.String(), "\n"), false
+	for i, r := range rows {
+		if r.MultipleTags {
+			lines[i+1], multi = yellow("%s", lines[i+1]), true
+		}
+	}
+	fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if multi {
+		log(yellow("highlighted images are running with more than one version."))
+	}
+	return
+}
+
+// imageRows aggregates images of RC templates and running pod containers.
+func imageRows(rcs []ReplicationController, pods []Pod) []ImageRow {
+	type set map[string]bool
+	type entry struct {
+		row                         ImageRow
+		digests, nodes, nss, owners set
+	}
+	entries := map[string]*entry{}
+	get := func(image string) *entry {
+		img, ver := parseImage(image)
+		key := img + " " + ver
+		if e, ok := entries[key]; ok {
+			return e
+		}
+		e := &entry{ImageRow{Image: img, Version: ver}, set{}, set{}, set{}, set{}}
+		entries[key] = e
+		return e
+	}
+
+	for _, rc := range rcs {
+		for _, c := range templateContainers(rc) {
+			e := get(c.Image)
+			e.nss[rc.Namespace] = true
+			e.owners[rc.Name] = true
+		}
+	}
+	running := map[string]set{}
+	for _, pod := range pods {
+		owners := []string{}
+		for _, rc := range rcs {
+			if rc.Namespace == pod.Namespace && Selector(rc.Spec.Selector).Matches(pod.Labels) {
+				owners = append(owners, rc.Name)
+			}
+		}
+		for _, c := range pod.Spec.Containers {
+			e := get(c.Image)
+			e.row.Pods++
+			e.nss[pod.Namespace] = true
+			if pod.Spec.NodeName != "" {
+				e.nodes[pod.Spec.NodeName] = true
+			}
+			if cs, ok := containerStatus(pod, c.Name); ok && cs.ImageID != "" {
+				e.digests[imageIDDigest(cs.ImageID)] = true
+			}
+			for _, o := range owners {
+				e.owners[o] = true
+			}
+			if running[e.row.Image] == nil {
+				running[e.row.Image] = set{}
+			}
+			running[e.row.Image][e.row.Version] = true
+		}
+	}
+
+	rows := make([]ImageRow, 0, len(entries))
+	for _, e := range entries {
+		r := e.row
+		r.Digests = sortedSet(e.digests)
+		r.Nodes = sortedSet(e.nodes)
+		r.Namespaces = sortedSet(e.nss)
+		r.RCs = sortedSet(e.owners)
+		r.MultipleTags = len(running[r.Image]) > 1
+		rows = append(rows, r)
+	}
+	sort.Sort(imageRowSorter(rows))
+	return rows
+}
+
+func sortedSet(s map[string]bool) []string {
+	list := []string{}
+	for k := range s {
+		if k != "" {
+			list = append(list, k)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func joinOrNone(list []string) string {
+	return orNone(strings.Join(list, ","))
+}
+
+func shortDigests(digests []string) []string {
+	list := make([]string, len(digests))
+	for i := range digests {
+		list[i] = shortDigest(digests[i])
+	}
+	return list
+}
+
+// imageRowSorter sorts rows by image name, then newer version first.
+type imageRowSorter []ImageRow
+
+func (s imageRowSorter) Len() int      { return len(s) }
+func (s imageRowSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s imageRowSorter) Less(i, j int) bool {
+	if s[i].Image != s[j].Image {
+		return s[i].Image < s[j].Image
+	}
+	return newerVersion(s[i].Version, s[j].Version)
+}
@@ -0,0 +1,51 @@
+package kube
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageRows(t *testing.T) {
+	rc := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	rc.Namespace = "default"
+	rc.Spec.Selector = map[string]string{"name": "web"}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-2", "nginx:1.9.0", ContainerState{}, 0),
+		testPod("db-1", "mysql:5.7", ContainerState{}, 0),
+	}
+	pods[0].Status.ContainerStatuses[0].ImageID = "docker://sha256:abc"
+	pods[1].Spec.NodeName = "node-2"
+	pods[2].Labels = map[string]string{"name": "db"}
+
+	rows := imageRows([]ReplicationController{rc}, pods)
+	require.Len(t, rows, 3)
+	assert.Equal(t, ImageRow{
+		Image: "mysql", Version: "5.7", Pods: 1, Digests: []string{}, Nodes: []string{"node-1"},
+		Namespaces: []string{"default"}, RCs: []string{},
+	}, rows[0])
+	assert.Equal(t, ImageRow{
+		Image: "nginx", Version: "1.9.1", Pods: 1, Digests: []string{"sha256:abc"}, Nodes: []string{"node-1"},
+		Namespaces: []string{"default"}, RCs: []string{"web"}, MultipleTags: true,
+	}, rows[1])
+	assert.Equal(t, "1.9.0", rows[2].Version)
+	assert.Equal(t, []string{"node-2"}, rows[2].Nodes)
+	assert.True(t, rows[2].MultipleTags)
+}
+
+func TestWriteListRows(t *testing.T) {
+	b := bytes.Buffer{}
+	out = &b
+	rows := []ImageRow{{Image: "nginx", Version: "1.9.1", Nodes: []string{"node-1", "node-2"}}}
+
+	require.NoError(t, writeRows(OutputCSV, rows))
+	assert.Contains(t, b.String(), "nginx,1.9.1,0,,\"node-1,node-2\",,,false\n")
+
+	b.Reset()
+	require.NoError(t, writeRows(OutputYAML, rows))
+	assert.Contains(t, b.String(), "  nodes: [node-1, node-2]\n")
+	assert.Contains(t, b.String(), "  digests: []\n")
+}
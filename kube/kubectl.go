@@ -5,34 +5,59 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // Kubectl executes kubectl as command.
 type Kubectl struct {
 	Debug     bool
 	Namespace string
+	// DryRun prints mutating commands instead of executing them.
+	DryRun bool
 }
 
 // Exec kubectl commands with arguments.
 func (kc *Kubectl) Exec(args ...string) (b []byte, err error) {
+	return kc.run(kc.namespaced(args)...)
+}
+
+// mutate executes kubectl commands which change cluster state.
+// Commands are only printed in dry-run mode.
+func (kc *Kubectl) mutate(args ...string) (b []byte, err error) {
+	args = kc.namespaced(args)
+	if kc.DryRun {
+		log(yellow("[dry-run]"), "kubectl", shellJoin(args))
+		return
+	}
+	return kc.run(args...)
+}
 
+func (kc *Kubectl) namespaced(args []string) []string {
 	if kc.Namespace == "all" {
-		args = append(args, "--all-namespace")
+		args = append(args, "--all-namespaces")
 	} else if kc.Namespace != "" {
 		args = append(args, "--namespace="+kc.Namespace)
 	}
+	return args
+}
 
+// run kubectl without namespace option.
+func (kc *Kubectl) run(args ...string) (b []byte, err error) {
 	stdout := bytes.Buffer{}
 	cmd := exec.Command("kubectl", args...)
 	if kc.Debug {
 		log("exec kubectl", args)
 	}
 	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = errOut
 	err = cmd.Run()
 	if err != nil {
 		return
@@ -41,6 +66,19 @@ func (kc *Kubectl) Exec(args ...string) (b []byte, err error) {
 
 }
 
+// shellJoin quotes arguments to be pasted on shell.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && regexp.MustCompile(`^[A-Za-z0-9_./:=,@%+-]+$`).MatchString(a) {
+			quoted[i] = a
+			continue
+		}
+		quoted[i] = "'" + strings.Replace(a, "'", `'\''`, -1) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
 func trim(text string) string {
 	return strings.Trim(text, " \n")
 }
@@ -78,6 +116,31 @@ func (kc *Kubectl) Version() (client string, server string, err error) {
 	return cliv[1], srvv[1], nil
 }
 
+// clientDryRun returns dry-run flag of kubectl create without contacting
+// server. kubectl 1.18 and later rejects bare --dry-run.
+func (kc *Kubectl) clientDryRun() (flag string, err error) {
+	b, err := kc.run("version", "--client")
+	if err != nil {
+		return
+	}
+	return dryRunFlag(string(b))
+}
+
+// dryRunFlag picks dry-run flag by output of kubectl version --client, which
+// is either version.Info{...GitVersion:"v1.3.0"...} or plain v1.28.0.
+func dryRunFlag(version string) (flag string, err error) {
+	m := regexp.MustCompile(`Client Version:\s*(?:version\.Info\{.*GitVersion:")?v(\d+)\.(\d+)`).FindStringSubmatch(version)
+	if m == nil {
+		return "", fmt.Errorf("client version not found in %q", strings.TrimSpace(version))
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major > 1 || minor >= 18 {
+		return "--dry-run=client", nil
+	}
+	return "--dry-run", nil
+}
+
 type rcList struct {
 	Items []ReplicationController
 }
@@ -107,9 +170,37 @@ func (kc Kubectl) RC(name string) (rc ReplicationController, err error) {
 	return
 }
 
+// RCFromFile decodes replication controller manifest written in yaml or json.
+func (kc *Kubectl) RCFromFile(path string) (rc ReplicationController, err error) {
+	var b []byte
+	if strings.HasSuffix(path, ".json") {
+		b, err = ioutil.ReadFile(path)
+	} else {
+		// let kubectl convert yaml into json.
+		var dryRun string
+		if dryRun, err = kc.clientDryRun(); err != nil {
+			return
+		}
+		b, err = kc.run("create", dryRun, "--output=json", "--filename="+path)
+	}
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(b, &rc); err != nil {
+		return
+	}
+	if rc.Kind != "ReplicationController" {
+		err = fmt.Errorf("%s is not ReplicationController but %s", path, rc.Kind)
+	}
+	return
+}
+
 // PatchRC updates RC fields.
 func (kc Kubectl) PatchRC(rc string, patch string) (err error) {
-	_, err = kc.Exec("patch", "rc", rc, "-p", patch)
+	if kc.DryRun {
+		logPatch("rc/"+rc, patch)
+	}
+	_, err = kc.mutate("patch", "rc", rc, "-p", patch)
 	return
 }
 
@@ -132,6 +223,259 @@ func (kc *Kubectl) PodList(selector Selector) (pods []Pod, err error) {
 	return list.Items, nil
 }
 
+type nodeList struct {
+	Items []Node
+}
+
+// NodeList return nodes.
+func (kc *Kubectl) NodeList() (nodes []Node, err error) {
+	// nodes are not namespaced.
+	b, err := kc.run("get", "node", "--output=json")
+	if err != nil {
+		return
+	}
+	list := nodeList{}
+	if err = json.Unmarshal(b, &list); err != nil {
+		err = errors.New(trim(string(b)))
+	}
+	return list.Items, err
+}
+
+// EventList return events.
+func (kc *Kubectl) EventList() (events []Event, err error) {
+	b, err := kc.Exec("get", "event", "--output=json")
+	if err != nil {
+		return
+	}
+	list := EventList{}
+	if err = json.Unmarshal(b, &list); err != nil {
+		err = errors.New(trim(string(b)))
+	}
+	return list.Items, err
+}
+
+// ConfigMap return single config map. found is false when it does not exist.
+func (kc *Kubectl) ConfigMap(name string) (cm ConfigMap, found bool, err error) {
+	args := kc.namespaced([]string{"get", "configmap", name, "--output=json"})
+	if kc.Debug {
+		log("exec kubectl", args)
+	}
+	// stderr is read to tell missing config map from other errors.
+	o, e := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdout, cmd.Stderr = &o, &e
+	if err = cmd.Run(); err != nil {
+		if notFound(e.String(), name) {
+			return cm, false, nil
+		}
+		errOut.Write(e.Bytes())
+		return
+	}
+	if err = json.Unmarshal(o.Bytes(), &cm); err != nil {
+		err = errors.New(trim(o.String()))
+		return
+	}
+	return cm, true, nil
+}
+
+// notFound checks error message of kubectl get like
+// Error from server (NotFound): configmaps "name" not found.
+func notFound(stderr string, name string) bool {
+	return strings.Contains(stderr, fmt.Sprintf(`"%s" not found`, name))
+}
+
+// SaveConfigMap creates config map, or replaces existing one when replace is true.
+func (kc *Kubectl) SaveConfigMap(cm ConfigMap, replace bool) (err error) {
+	verb := "create"
+	if replace {
+		verb = "replace"
+	}
+	if kc.DryRun {
+		// config map would be written to stdin.
+		_, err = kc.mutate(verb, "--filename=-")
+		return
+	}
+	b, err := json.Marshal(cm)
+	if err != nil {
+		return
+	}
+	f, err := ioutil.TempFile("", "kubetool-configmap")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	f.Close()
+	if err != nil {
+		return
+	}
+	_, err = kc.mutate(verb, "--filename="+f.Name())
+	return
+}
+
+// SecretList return secrets. Values of secrets must not be printed without care.
+func (kc *Kubectl) SecretList() (secrets []Secret, err error) {
+	b, err := kc.Exec("get", "secret", "--output=json")
+	if err != nil {
+		return
+	}
+	list := SecretList{}
+	if err = json.Unmarshal(b, &list); err != nil {
+		// output may contain values of secrets.
+		err = errors.New("failed to parse secrets")
+	}
+	return list.Items, err
+}
+
+// ServiceList return services.
+func (kc *Kubectl) ServiceList() (services []Service, err error) {
+	b, err := kc.Exec("get", "service", "--output=json")
+	if err != nil {
+		return
+	}
+	list := ServiceList{}
+	if err = json.Unmarshal(b, &list); err != nil {
+		err = errors.New(trim(string(b)))
+	}
+	return list.Items, err
+}
+
+// EndpointsList return endpoints of services.
+func (kc *Kubectl) EndpointsList() (endpoints []Endpoints, err error) {
+	b, err := kc.Exec("get", "endpoints", "--output=json")
+	if err != nil {
+		return
+	}
+	list := EndpointsList{}
+	if err = json.Unmarshal(b, &list); err != nil {
+		err = errors.New(trim(string(b)))
+	}
+	return list.Items, err
+}
+
+// ExecPod runs command in container of pod. code is exit code of command.
+// err is returned only when kubectl can not run. Command is only printed in dry-run mode.
+func (kc *Kubectl) ExecPod(pod string, container string, command []string) (stdout []byte, stderr []byte, code int, err error) {
+	// namespace option must be placed before command.
+	args := kc.namespaced([]string{"exec", pod, "--container=" + container})
+	args = append(append(args, "--"), command...)
+	if kc.DryRun {
+		log(yellow("[dry-run]"), "kubectl", shellJoin(args))
+		return
+	}
+	if kc.Debug {
+		log("exec kubectl", args)
+	}
+	o, e := bytes.Buffer{}, bytes.Buffer{}
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdout, cmd.Stderr = &o, &e
+	err = cmd.Run()
+	if exit, ok := err.(*exec.ExitError); ok {
+		if status, ok := exit.Sys().(syscall.WaitStatus); ok {
+			code, err = status.ExitStatus(), nil
+		}
+	}
+	return o.Bytes(), e.Bytes(), code, err
+}
+
+// Logs streams logs of pod. Closing stream stops kubectl.
+func (kc *Kubectl) Logs(pod string, opts PodLogOptions) (stream io.ReadCloser, err error) {
+	args := kc.namespaced(podLogArgs(pod, opts))
+	if kc.Debug {
+		log("exec kubectl", args)
+	}
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stderr = errOut
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	return &logStream{r, cmd}, nil
+}
+
+// podLogArgs converts log options into kubectl arguments.
+func podLogArgs(pod string, opts PodLogOptions) []string {
+	args := []string{"logs", pod}
+	if opts.Container != "" {
+		args = append(args, "--container="+opts.Container)
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Previous {
+		args = append(args, "--previous")
+	}
+	if opts.SinceSeconds != nil {
+		args = append(args, fmt.Sprintf("--since=%ds", *opts.SinceSeconds))
+	}
+	if opts.SinceTime != nil {
+		args = append(args, "--since-time="+opts.SinceTime.UTC().Format(time.RFC3339))
+	}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	if opts.TailLines != nil {
+		args = append(args, fmt.Sprintf("--tail=%d", *opts.TailLines))
+	}
+	if opts.LimitBytes != nil {
+		args = append(args, fmt.Sprintf("--limit-bytes=%d", *opts.LimitBytes))
+	}
+	return args
+}
+
+type logStream struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (s *logStream) Close() error {
+	s.cmd.Process.Kill()
+	return s.cmd.Wait()
+}
+
+// Watch starts watching objects of kind and notifies on each change event.
+// Events are coalesced, and channel is closed when watch ends.
+// stop kills underlying kubectl.
+func (kc *Kubectl) Watch(kind string, selector Selector) (events <-chan struct{}, stop func()) {
+	args := []string{"get", kind, "--watch-only", "--output=json"}
+	if len(selector) > 0 {
+		args = append(args, "--selector="+selector.Format())
+	}
+	args = kc.namespaced(args)
+	if kc.Debug {
+		log("exec kubectl", args)
+	}
+	ch := make(chan struct{}, 1)
+	cmd := exec.Command("kubectl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		close(ch)
+		return ch, func() {}
+	}
+	go func() {
+		defer close(ch)
+		dec := json.NewDecoder(stdout)
+		for {
+			var obj json.RawMessage
+			if err := dec.Decode(&obj); err != nil {
+				cmd.Wait()
+				return
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch, func() { cmd.Process.Kill() }
+}
+
 // Pod return single pod.
 func (kc *Kubectl) Pod(name string) (pod Pod, err error) {
 	b, err := kc.Exec("get", "pod", name, "--output=json")
@@ -144,12 +488,34 @@ func (kc *Kubectl) Pod(name string) (pod Pod, err error) {
 	return
 }
 
+// CordonNode marks node unschedulable, or schedulable again when unschedulable is false.
+func (kc Kubectl) CordonNode(node string, unschedulable bool) (err error) {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	if kc.DryRun {
+		logPatch("node/"+node, patch)
+	}
+	// nodes are not namespaced.
+	kc.Namespace = ""
+	_, err = kc.mutate("patch", "node", node, "-p", patch)
+	return
+}
+
 // DeletePod in cluster
 func (kc *Kubectl) DeletePod(name string) (err error) {
-	_, err = kc.Exec("delete", "pod", name)
+	_, err = kc.mutate("delete", "pod", name)
 	return
 }
 
+// logPatch writes indented patch for dry-run.
+func logPatch(target string, patch string) {
+	b := bytes.Buffer{}
+	if err := json.Indent(&b, []byte(patch), "", "  "); err != nil {
+		b.WriteString(patch)
+	}
+	log(yellow("[dry-run]"), "patch", blue(target)+":")
+	log(b.String())
+}
+
 // Selector map.
 type Selector map[string]string
 
@@ -161,3 +527,17 @@ func (s Selector) Format() string {
 	}
 	return strings.Join(list, ",")
 }
+
+// Matches returns true when labels have all key-values of selector.
+// Empty selector matches nothing.
+func (s Selector) Matches(labels map[string]string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for k, v := range s {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
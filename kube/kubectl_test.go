@@ -115,3 +115,34 @@ func TestPatch(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "kubetool", rc.ObjectMeta.Labels["test"])
 }
+
+func TestShellJoin(t *testing.T) {
+	require.Equal(t, `delete pod nginx-6k8hq --namespace=default`, shellJoin([]string{"delete", "pod", "nginx-6k8hq", "--namespace=default"}))
+	require.Equal(t, `patch rc nginx -p '{"spec":{}}' ''`, shellJoin([]string{"patch", "rc", "nginx", "-p", `{"spec":{}}`, ""}))
+	require.Equal(t, `'it'\''s'`, shellJoin([]string{"it's"}))
+}
+
+func TestDryRunFlag(t *testing.T) {
+	f, err := dryRunFlag(`Client Version: version.Info{Major:"1", Minor:"3", GitVersion:"v1.3.0", GitCommit:"283137936a498aed572ee22af6774b6fb6e9fd94"}`)
+	require.NoError(t, err)
+	require.Equal(t, "--dry-run", f)
+	f, err = dryRunFlag("Client Version: v1.28.2\nKustomize Version: v5.0.4\n")
+	require.NoError(t, err)
+	require.Equal(t, "--dry-run=client", f)
+	_, err = dryRunFlag("error: unknown flag")
+	require.Error(t, err)
+}
+
+func TestNotFound(t *testing.T) {
+	require.True(t, notFound(`Error from server (NotFound): configmaps "nginx" not found`, "nginx"))
+	require.True(t, notFound(`Error from server: configmaps "nginx" not found`, "nginx"))
+	require.False(t, notFound(`error: the server doesn't have a resource type "configmap"`, "nginx"))
+	require.False(t, notFound(`Error from server (Forbidden): configmaps "nginx" is forbidden`, "nginx"))
+}
+
+func TestSelectorMatches(t *testing.T) {
+	s := Selector{"name": "web", "tier": "front"}
+	require.True(t, s.Matches(map[string]string{"name": "web", "tier": "front", "x": "y"}))
+	require.False(t, s.Matches(map[string]string{"name": "web"}))
+	require.False(t, Selector{}.Matches(map[string]string{"name": "web"}))
+}
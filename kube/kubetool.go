@@ -6,11 +6,11 @@ import (
 	"io"
 	"os"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/abema/kubetool/registry"
 	"github.com/buger/goterm"
 	"github.com/fatih/color"
 )
@@ -27,6 +27,8 @@ var (
 	bold    = color.New(color.Bold).SprintfFunc()
 
 	out io.Writer
+	// errOut is stderr of kubectl.
+	errOut io.Writer
 )
 
 // Tool is to execute batch tasks using kubectl command.
@@ -36,10 +38,19 @@ type Tool struct {
 	force     bool
 	interval  int
 	minStable float64
+	pinDigest bool
+	downgrade bool
+	dryRun    bool
+	output    string
+	template  string
+	columns   string
+	filter    ListFilter
+	registry  registry.Client
 }
 
 func init() {
 	out = os.Stdout
+	errOut = os.Stderr
 }
 
 // SetNamespace to define target namespace.
@@ -67,6 +78,58 @@ func (t *Tool) SetMinimumStable(minStable float64) {
 	t.minStable = minStable
 }
 
+// SetDryRun to print planned changes without executing them.
+func (t *Tool) SetDryRun(dryRun bool) {
+	t.dryRun = dryRun
+	t.kubectl.DryRun = dryRun
+}
+
+// SetOutput format of listings. One of OutputFormats or empty for table.
+func (t *Tool) SetOutput(format string) {
+	t.output = format
+}
+
+// SetTemplate of go template to print each object of listings.
+func (t *Tool) SetTemplate(template string) {
+	t.template = template
+}
+
+// SetColumns of custom columns like NAME:.metadata.name,NODE:.spec.nodeName
+// to print listings.
+func (t *Tool) SetColumns(columns string) {
+	t.columns = columns
+}
+
+// SetFilter to narrow and sort rows of listings.
+func (t *Tool) SetFilter(filter ListFilter) {
+	t.filter = filter
+}
+
+// SetPinDigest to pin image with digest resolved from registry on update.
+func (t *Tool) SetPinDigest(pin bool) {
+	t.pinDigest = pin
+}
+
+// SetAllowDowngrade to allow computed version older than current one.
+func (t *Tool) SetAllowDowngrade(allow bool) {
+	t.downgrade = allow
+}
+
+// SetInsecureRegistry to request registries via plain http.
+func (t *Tool) SetInsecureRegistry(insecure bool) {
+	t.registry.Insecure = insecure
+}
+
+// SetDockerConfig to read registry credentials from docker config file.
+func (t *Tool) SetDockerConfig(path string) (err error) {
+	auth, err := registry.DockerConfigAuth(path)
+	if err != nil {
+		return
+	}
+	t.registry.Auth = auth
+	return
+}
+
 // PrintInfo writes version of target cluster.
 func (t *Tool) PrintInfo() (err error) {
 	c, s, err := t.kubectl.Version()
@@ -96,60 +159,117 @@ func (t *Tool) PrintPodList(rcname string) (err error) {
 		selector = Selector{"name": rcname}
 	}
 
+	if err = t.filter.validate("pod"); err != nil {
+		return
+	}
 	pods, err := t.kubectl.PodList(selector)
 	if err != nil {
 		return
 	}
+	rows := t.filter.pods(podRows(pods))
+	// custom output follows filtered and sorted rows.
+	podMap := map[string]Pod{}
+	for i := range pods {
+		podMap[pods[i].Namespace+"/"+pods[i].Name] = pods[i]
+	}
+	objs, data := []interface{}{}, []interface{}{}
+	done := map[string]bool{}
+	for _, r := range rows {
+		key := r.Namespace + "/" + r.Name
+		if !done[key] {
+			done[key] = true
+			objs, data = append(objs, podMap[key]), append(data, newPodTemplateData(podMap[key]))
+		}
+	}
+	if printed, err := t.printCustom(objs, data); printed {
+		return err
+	}
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	fmt.Fprintln(out, t.podTable(rows))
+	return
+}
+
+// podTable formats pod rows in default or wide table.
+func (t *Tool) podTable(rows []PodRow) string {
 	w := goterm.NewTable(0, 4, 1, ' ', 0)
-	fmt.Fprintf(w, "NAME\tSTATUS\tR\tPOD IP\tNODE IP\tIMAGE\tVERSION\n")
-	for _, pod := range pods {
-		for i, container := range pod.Spec.Containers {
-			cstate := pod.Status.ContainerStatuses[i].State
-			status := "Running"
-			if cstate.Terminated != nil {
-				status = "Terminated"
-			} else if cstate.Waiting != nil {
-				status = "Waiting"
-			}
-			img, ver := parseImage(container.Image)
-			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
-				pod.Name,
-				status,
-				pod.Status.ContainerStatuses[i].RestartCount,
-				pod.Status.PodIP,
-				pod.Status.HostIP,
-				img, ver,
+	if t.output == OutputWide {
+		fmt.Fprintf(w, "NAMESPACE\tNAME\tCONTAINER\tSTATUS\tREADY\tR\tPOD IP\tNODE IP\tNODE\tIMAGE\tVERSION\tDIGEST\tAGE\n")
+	} else {
+		fmt.Fprintf(w, "NAME\tSTATUS\tR\tPOD IP\tNODE IP\tIMAGE\tVERSION\tDIGEST\n")
+	}
+	for _, r := range rows {
+		if t.output == OutputWide {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Namespace, r.Name, r.Container, r.Status, r.Ready, r.Restarts,
+				r.IP, r.NodeIP, r.Node, r.Image, r.Version, shortDigest(r.Digest), r.Age,
 			)
+			continue
 		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, r.Status, r.Restarts, r.IP, r.NodeIP, r.Image, r.Version, shortDigest(r.Digest),
+		)
 	}
-	fmt.Println(w.String())
-	return
+	return w.String()
 }
 
 // PrintRCList print images of running RCs.
 func (t *Tool) PrintRCList() (err error) {
+	if err = t.filter.validate("rc"); err != nil {
+		return
+	}
 	rcs, err := t.kubectl.RCList()
 	if err != nil {
 		return
 	}
-	w := goterm.NewTable(0, 4, 1, ' ', 0)
-	fmt.Fprintf(w, "NAME\tREPLICAS\tIMAGE\tVERSION\n")
-	for _, rc := range rcs {
-		rcSpec := rc.Spec
-		if rcSpec.Template == nil {
-			continue
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows := t.filter.rcs(t.rcRows(rcs, pods))
+	// custom output follows filtered and sorted rows.
+	rcMap := map[string]ReplicationController{}
+	for i := range rcs {
+		rcMap[rcs[i].Namespace+"/"+rcs[i].Name] = rcs[i]
+	}
+	objs, data := []interface{}{}, []interface{}{}
+	done := map[string]bool{}
+	for _, r := range rows {
+		key := r.Namespace + "/" + r.Name
+		if !done[key] {
+			done[key] = true
+			objs, data = append(objs, rcMap[key]), append(data, newRCTemplateData(rcMap[key]))
 		}
-		podSpec := rcSpec.Template.Spec
-		for _, container := range podSpec.Containers {
-			img, ver := parseImage(container.Image)
-			fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\n",
-				rc.Name, rc.Status.Replicas, *rcSpec.Replicas, img, ver,
+	}
+	if printed, err := t.printCustom(objs, data); printed {
+		return err
+	}
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	fmt.Fprintln(out, t.rcTable(rows))
+	return
+}
+
+// rcTable formats RC rows in default or wide table.
+func (t *Tool) rcTable(rows []RCRow) string {
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	if t.output == OutputWide {
+		fmt.Fprintf(w, "NAMESPACE\tNAME\tCONTAINER\tREPLICAS\tREADY\tIMAGE\tVERSION\tAGE\n")
+	} else {
+		fmt.Fprintf(w, "NAME\tREPLICAS\tIMAGE\tVERSION\n")
+	}
+	for _, r := range rows {
+		if t.output == OutputWide {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d\t%s\t%s\t%s\n",
+				r.Namespace, r.Name, r.Container, r.Current, r.Replicas, r.Ready, r.Image, r.Version, r.Age,
 			)
+			continue
 		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\n", r.Name, r.Current, r.Replicas, r.Image, r.Version)
 	}
-	fmt.Println(w.String())
-	//goterm.Println(w)
-	return
+	return w.String()
 }
 
 // Reload all or one pod(s) in single rc.
@@ -222,22 +342,94 @@ func (t *Tool) Update(name string, container string, version string) (err error)
 		}
 	}
 
+	newImage := img + ":" + version
 	log("Image    :", magenta(img)+":"+yellow(ver))
 	log("       ->:", magenta(img)+":"+bold(yellow(version)))
+	if t.pinDigest {
+		var digest string
+		digest, err = t.registry.Digest(registry.ParseReference(newImage))
+		if err != nil {
+			return
+		}
+		newImage += "@" + digest
+		log("Digest   :", gray(digest))
+	}
 	t.confirm("continue?")
 
-	newImage := img + ":" + version
-
 	//t.kubectl.Patch
 	patch := fmt.Sprintf(`{"spec":{"template":{"spec":{"containers":[{"name":"%s","image":"%s"}]}}}}`, c.Name, newImage)
-	if err = t.kubectl.PatchRC(rc.Name, patch); err != nil {
+	err = t.patchRC(rc.Name, patch)
+	return
+}
+
+// patchRC applies patch to RC. Patch is only printed in dry-run mode.
+func (t *Tool) patchRC(name string, patch string) (err error) {
+	if err = t.kubectl.PatchRC(name, patch); err != nil {
+		return
+	}
+	if t.dryRun {
+		log(yellow("[dry-run] patch is not applied"))
 		return
 	}
 	log(green("Successfully patched"))
 	return
 }
 
+// UpdateTo updates RC image to the newest version satisfying constraint.
+// Candidate versions are listed from registry when candidates is empty.
+func (t *Tool) UpdateTo(name string, container string, c registry.Constraint, candidates []string) (err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	ct, err := pickContainer(rc, container)
+	if err != nil {
+		return
+	}
+	img, current := parseImage(ct.Image)
+	if len(candidates) == 0 {
+		if candidates, err = t.registry.Tags(registry.ParseReference(img)); err != nil {
+			return
+		}
+	}
+	version, err := c.Latest(current, candidates)
+	if err != nil {
+		return
+	}
+	if registry.IsDowngrade(current, version) && !t.downgrade {
+		return fmt.Errorf("%s is older than current version %s. Use --allow-downgrade to update", version, current)
+	}
+	if version == current {
+		log(green("already up to date:"), yellow(current))
+		return
+	}
+	return t.Update(name, container, version)
+}
+
+// maxVersions is number of versions listed at once in version picker.
+const maxVersions = 20
+
+// selectVersion lists tags of image from registry and asks version to user.
+// Versions of running pods are listed when registry is not available.
 func (t *Tool) selectVersion(rc ReplicationController, container string) (version string, err error) {
+	c, err := pickContainer(rc, container)
+	if err != nil {
+		return
+	}
+	img, current := parseImage(c.Image)
+	vers, err := t.registry.Tags(registry.ParseReference(img))
+	if err != nil {
+		log(yellow("failed to list tags from registry:"), err)
+		if vers, err = t.podVersions(rc, container); err != nil {
+			return
+		}
+	}
+	registry.SortTags(vers)
+	return pickVersion(vers, current)
+}
+
+// podVersions returns versions used by running pods of RC.
+func (t *Tool) podVersions(rc ReplicationController, container string) (vers []string, err error) {
 	pods, err := t.kubectl.PodList(Selector{"name": rc.Name})
 	if err != nil {
 		return
@@ -246,37 +438,81 @@ func (t *Tool) selectVersion(rc ReplicationController, container string) (versio
 	for i := range pods {
 		c, err := pickPodContainer(pods[i], container)
 		if err != nil {
-			return version, err
+			return vers, err
 		}
 		_, ver := parseImage(c.Image)
 		vermap[ver]++
 	}
-	vers := make([]string, 0, len(vermap))
+	vers = make([]string, 0, len(vermap))
 	for k := range vermap {
 		vers = append(vers, k)
 	}
-	sort.Strings(vers)
-	log("Current versions")
-	for i, ver := range vers {
-		logf("[%s] - %s", blue("%d", i), yellow(ver))
-	}
+	return
+}
 
-	fmt.Print("choose or input version: ")
-	res := ""
-	fmt.Scanf("%s", &res)
-	// if number set, use version from list
-	if regexp.MustCompile("^[0-9]+$").MatchString(res) {
-		idx, err := strconv.Atoi(res)
-		if err != nil {
-			return "", err
+// pickVersion asks version to user via terminal. Number selects version from
+// list, text filters list and unknown version is used as it is.
+func pickVersion(vers []string, current string) (version string, err error) {
+	filter := ""
+	for {
+		list := filterVersions(vers, filter)
+		if filter == "" {
+			log("Versions")
+		} else {
+			log("Versions matching", yellow(filter))
+		}
+		for i, ver := range list {
+			if i >= maxVersions {
+				log(gray(fmt.Sprintf("... %d more, type to filter", len(list)-maxVersions)))
+				break
+			}
+			if ver == current {
+				logf("[%s] - %s %s", blue("%d", i), bold(green(ver)), gray("(current)"))
+				continue
+			}
+			logf("[%s] - %s", blue("%d", i), yellow(ver))
+		}
+
+		fmt.Print("choose, filter or input version: ")
+		res := ""
+		fmt.Scanln(&res)
+		res = strings.Trim(res, " \n\t")
+		switch {
+		case res == "" && filter == "":
+			return "", errors.New("no version selected")
+		case res == "":
+			// reset filter
+			filter = ""
+		case regexp.MustCompile("^[0-9]+$").MatchString(res) && !contains(res, vers):
+			// if number set, use version from list
+			idx, err := strconv.Atoi(res)
+			if err != nil {
+				return "", err
+			}
+			if idx < 0 || idx >= len(list) || idx >= maxVersions {
+				return "", errors.New("version index out of range")
+			}
+			return list[idx], nil
+		case contains(res, vers) || len(filterVersions(vers, res)) == 0:
+			return res, nil
+		default:
+			filter = res
 		}
-		if idx < 0 || idx >= len(vers) {
-			err = errors.New("version index out of range")
-			return "", err
+	}
+}
+
+// filterVersions returns versions which contain filter text.
+func filterVersions(vers []string, filter string) []string {
+	if filter == "" {
+		return vers
+	}
+	list := []string{}
+	for _, ver := range vers {
+		if strings.Contains(ver, filter) {
+			list = append(list, ver)
 		}
-		return vers[idx], nil
 	}
-	return strings.Trim(res, " \n\t"), nil
+	return list
 }
 
 // FixVersion of pods running on RC with destroying all pods that has
@@ -293,16 +529,8 @@ func (t *Tool) FixVersion(name string) (err error) {
 	pods := []Pod{}
 	rspec := rc.Spec.Template.Spec
 	for _, pod := range allPods {
-		// when containers has different size, move on
-		if len(pod.Spec.Containers) != len(rspec.Containers) {
+		if outdated(rc, pod) {
 			pods = append(pods, pod)
-			continue
-		}
-		for i, cs := range pod.Spec.Containers {
-			if cs.Image != rspec.Containers[i].Image {
-				pods = append(pods, pod)
-				break
-			}
 		}
 	}
 
@@ -334,6 +562,21 @@ func (t *Tool) FixVersion(name string) (err error) {
 	return
 }
 
+// outdated returns true when pod runs images different from RC ones.
+func outdated(rc ReplicationController, pod Pod) bool {
+	cs := templateContainers(rc)
+	// when containers has different size, move on
+	if len(pod.Spec.Containers) != len(cs) {
+		return true
+	}
+	for i, c := range pod.Spec.Containers {
+		if c.Image != cs[i].Image {
+			return true
+		}
+	}
+	return false
+}
+
 // reloadPods deletes pods one by one with waiting created pod become available.
 func (t *Tool) reloadPods(rc ReplicationController, pods []Pod) (err error) {
 
@@ -352,7 +595,7 @@ func (t *Tool) reloadPods(rc ReplicationController, pods []Pod) (err error) {
 	}
 	// delete dead pods first without waiting availability.
 	for i := range deadPods {
-		logf("deleting pod %s...", red(deadPods[i].Name))
+		logf("deleting pod %s... %s", red(deadPods[i].Name), gray("(unavailable)"))
 		if err = t.kubectl.DeletePod(deadPods[i].Name); err != nil {
 			return
 		}
@@ -364,7 +607,7 @@ func (t *Tool) reloadPods(rc ReplicationController, pods []Pod) (err error) {
 
 	// delete pods one by one.
 	for i := range livePods {
-		logf("deleting pod %s...", green(livePods[i].Name))
+		logf("deleting pod %s... %s", green(livePods[i].Name), gray("(available)"))
 		if err = t.kubectl.DeletePod(livePods[i].Name); err != nil {
 			return
 		}
@@ -374,10 +617,18 @@ func (t *Tool) reloadPods(rc ReplicationController, pods []Pod) (err error) {
 			return
 		}
 		if t.interval > 0 {
+			if t.dryRun {
+				logf("%s sleep %d seconds", yellow("[dry-run]"), t.interval)
+				continue
+			}
 			time.Sleep(time.Duration(t.interval) * time.Second)
 		}
 	}
 
+	if t.dryRun {
+		log(yellow("[dry-run] no pods are deleted"))
+		return
+	}
 	log(green("done reloading pods"))
 	return
 }
@@ -388,8 +639,21 @@ func (t *Tool) waitRCAvailable(name string, ignoreNames []string) (err error) {
 	if t.force {
 		return
 	}
+	if t.dryRun {
+		rc, err := t.kubectl.RC(name)
+		if err != nil {
+			return err
+		}
+		logf("%s wait until rc %s has more than %s available pods excluding %d deleted",
+			yellow("[dry-run]"), blue(name), blue("%d", t.requiredPods(rc)), len(ignoreNames))
+		return nil
+	}
 	first := true
 	avail := false
+	// warnings of pods are printed while waiting, except existing ones.
+	// events are optional.
+	existing, _ := t.kubectl.EventList()
+	tracker := newEventTracker(existing)
 	// wait for about a minute to available all pods includes recreated.
 	for i := 0; i < 10; i++ {
 		// get RC again and check pod statuses
@@ -404,6 +668,8 @@ func (t *Tool) waitRCAvailable(name string, ignoreNames []string) (err error) {
 		if first {
 			first = false
 		}
+		// events are optional.
+		t.printNewEvents(rc, tracker, true)
 		// wait for 5 seconds
 		time.Sleep(time.Second * 5)
 	}
@@ -414,16 +680,42 @@ func (t *Tool) waitRCAvailable(name string, ignoreNames []string) (err error) {
 	return
 }
 
+// parseImage splits image into name and tag.
+// Digest is used as version when image is pinned without tag.
 func parseImage(img string) (name string, version string) {
 	name = img
 	version = "latest"
-	if lastColon := strings.LastIndexByte(img, ':'); lastColon > 0 {
-		name = img[:lastColon]
-		version = img[lastColon+1:]
+	if at := strings.IndexByte(name, '@'); at > 0 {
+		version = name[at+1:]
+		name = name[:at]
+	}
+	if lastColon := strings.LastIndexByte(name, ':'); lastColon > strings.LastIndexByte(name, '/') {
+		version = name[lastColon+1:]
+		name = name[:lastColon]
 	}
 	return
 }
 
+// imageIDDigest extracts digest from ContainerStatus.ImageID such as
+// "docker-pullable://nginx@sha256:..." or "docker://sha256:...".
+func imageIDDigest(imageID string) string {
+	if at := strings.LastIndexByte(imageID, '@'); at >= 0 {
+		return imageID[at+1:]
+	}
+	if i := strings.Index(imageID, "://"); i >= 0 {
+		return imageID[i+3:]
+	}
+	return imageID
+}
+
+// shortDigest truncates digest hex into 12 characters.
+func shortDigest(digest string) string {
+	if colon := strings.IndexByte(digest, ':'); colon >= 0 && len(digest) > colon+13 {
+		return digest[:colon+13]
+	}
+	return digest
+}
+
 // pickContainer from pods.
 func pickContainer(rc ReplicationController, container string) (c Container, err error) {
 	cs := rc.Spec.Template.Spec.Containers
@@ -454,8 +746,6 @@ func pickPodContainer(pod Pod, container string) (c Container, err error) {
 
 // check rc status.
 func (t *Tool) rcAvailable(rc ReplicationController, ignorePods []string) bool {
-	// check pods count reaches rc desied.
-	total := int(*rc.Spec.Replicas)
 	// check all pod status.
 	pods, err := t.kubectl.PodList(rc.Spec.Selector)
 	if err != nil {
@@ -463,13 +753,7 @@ func (t *Tool) rcAvailable(rc ReplicationController, ignorePods []string) bool {
 		return false
 	}
 	// minimum available requirement pods
-	reqNum := int(float64(total) * t.minStable)
-	if reqNum < 1 {
-		reqNum = 1
-	}
-	if reqNum > total {
-		reqNum = total
-	}
+	reqNum := t.requiredPods(rc)
 	// count available pods
 	availCount := 0
 	for i := range pods {
@@ -489,6 +773,19 @@ func (t *Tool) rcAvailable(rc ReplicationController, ignorePods []string) bool {
 	return false
 }
 
+// requiredPods returns minimum number of available pods to detect RC is stable.
+func (t *Tool) requiredPods(rc ReplicationController) int {
+	total := int(*rc.Spec.Replicas)
+	reqNum := int(float64(total) * t.minStable)
+	if reqNum < 1 {
+		reqNum = 1
+	}
+	if reqNum > total {
+		reqNum = total
+	}
+	return reqNum
+}
+
 func contains(item string, list []string) bool {
 	for i := range list {
 		if list[i] == item {
@@ -524,10 +821,10 @@ func fail(err error) {
 
 // confirm user input via terminal.
 func (t *Tool) confirm(msg string) {
-	if t.yes {
+	if t.yes || t.dryRun {
 		return
 	}
-	fmt.Printf(msg + " (y/N) ")
+	fmt.Print(msg + " (y/N) ")
 	res := ""
 	fmt.Scanf("%s", &res)
 	if !strings.Contains(strings.ToLower(res), "y") {
@@ -2,7 +2,10 @@ package kube
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -127,3 +130,85 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(t, "nginx:1.9.12", rc.Spec.Template.Spec.Containers[0].Image)
 
 }
+
+func TestParseImage(t *testing.T) {
+	cases := [][3]string{
+		{"nginx", "nginx", "latest"},
+		{"nginx:1.9.1", "nginx", "1.9.1"},
+		{"localhost:5000/nginx", "localhost:5000/nginx", "latest"},
+		{"localhost:5000/nginx:1.9", "localhost:5000/nginx", "1.9"},
+		{"nginx:1.9.1@sha256:abc", "nginx", "1.9.1"},
+		{"nginx@sha256:abc", "nginx", "sha256:abc"},
+	}
+	for _, c := range cases {
+		img, ver := parseImage(c[0])
+		assert.Equal(t, c[1], img, c[0])
+		assert.Equal(t, c[2], ver, c[0])
+	}
+}
+
+func TestImageIDDigest(t *testing.T) {
+	assert.Equal(t, "sha256:0123", imageIDDigest("docker-pullable://nginx@sha256:0123"))
+	assert.Equal(t, "sha256:0123", imageIDDigest("docker://sha256:0123"))
+	assert.Equal(t, "sha256:0123456789ab", shortDigest("sha256:0123456789abcdef"))
+	assert.Equal(t, "", shortDigest(""))
+}
+
+func TestFilterVersions(t *testing.T) {
+	vers := []string{"1.10.0", "1.9.2", "1.9.1", "latest"}
+	assert.Equal(t, vers, filterVersions(vers, ""))
+	assert.Equal(t, []string{"1.9.2", "1.9.1"}, filterVersions(vers, "1.9"))
+	assert.Empty(t, filterVersions(vers, "2.0"))
+}
+
+func TestSetDryRun(t *testing.T) {
+	kt := Tool{}
+	assert.False(t, kt.dryRun)
+	kt.SetDryRun(true)
+	assert.True(t, kt.dryRun)
+	assert.True(t, kt.kubectl.DryRun)
+}
+
+// stubKubectl puts a kubectl script serving rc as "kubetool-test" on PATH.
+// Arguments of each call are appended to the returned log file.
+func stubKubectl(t *testing.T, rc ReplicationController) (calls string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "kubetool-test")
+	require.NoError(t, err)
+	b, err := json.Marshal(rc)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "rc.json"), b, 0644))
+	calls = filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> "` + calls + `"
+case "$*" in
+"config current-context") echo test ;;
+"get rc kubetool-test --output=json") cat "` + filepath.Join(dir, "rc.json") + `" ;;
+*) exit 1 ;;
+esac
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755))
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return calls, func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDryRunUpdate(t *testing.T) {
+	rc := testManifestRC(1, "nginx:1.7.9", nil, nil)
+	rc.Name = "kubetool-test"
+	calls, cleanup := stubKubectl(t, rc)
+	defer cleanup()
+
+	b := bytes.Buffer{}
+	out = &b
+	kt := Tool{}
+	kt.SetDryRun(true)
+	require.NoError(t, kt.Update("kubetool-test", "", "1.9.0"))
+	assert.Contains(t, b.String(), "kubectl patch rc kubetool-test -p")
+
+	called, err := ioutil.ReadFile(calls)
+	require.NoError(t, err)
+	assert.NotContains(t, string(called), "patch")
+}
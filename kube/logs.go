@@ -0,0 +1,163 @@
+package kube
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// podColors are colors of pod name prefixed to log lines.
+var podColors = []func(string, ...interface{}) string{cyan, green, yellow, magenta, blue}
+
+// maxLogLine is maximum length of a log line.
+const maxLogLine = 1024 * 1024
+
+// Logs prints logs of all pods of RC prefixed with pod name. Only lines
+// matching grep are printed when it is given. When opts.Follow is set, logs
+// are streamed at once and pods created later like replacements are followed too.
+func (t *Tool) Logs(name string, opts PodLogOptions, grep string) (err error) {
+	var re *regexp.Regexp
+	if grep != "" {
+		if re, err = regexp.Compile(grep); err != nil {
+			return
+		}
+	}
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	c, err := pickContainer(rc, opts.Container)
+	if err != nil {
+		return
+	}
+	opts.Container = c.Name
+	pods, err := t.kubectl.PodList(rc.Spec.Selector)
+	if err != nil {
+		return
+	}
+
+	if !opts.Follow {
+		for i, pod := range pods {
+			prefix := podColors[i%len(podColors)]("[%s]", pod.Name)
+			emit := func(line string) { fmt.Fprintln(out, line) }
+			if err = t.streamLogs(pod.Name, opts, prefix, re, emit); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	// done stops followers blocked on sending lines after returning.
+	done := make(chan struct{})
+	defer close(done)
+	lines := make(chan string)
+	ended := make(chan string)
+	prefixes := map[string]string{}
+	// restarts of container when it was followed, by pod name.
+	followed := map[string]int32{}
+	streaming := map[string]bool{}
+	follow := func(pods []Pod) {
+		for _, pod := range pods {
+			if streaming[pod.Name] || !shouldFollow(pod, opts.Container, followed) {
+				continue
+			}
+			prefix, ok := prefixes[pod.Name]
+			if !ok {
+				prefix = podColors[len(prefixes)%len(podColors)]("[%s]", pod.Name)
+				prefixes[pod.Name] = prefix
+			} else {
+				fmt.Fprintln(out, prefix, gray("following restarted container"))
+			}
+			cs, _ := containerStatus(pod, opts.Container)
+			followed[pod.Name] = cs.RestartCount
+			streaming[pod.Name] = true
+			go func(name string) {
+				send := func(line string) {
+					select {
+					case lines <- line:
+					case <-done:
+					}
+				}
+				if err := t.streamLogs(name, opts, prefix, re, send); err != nil {
+					send(fmt.Sprintf("%s %s", prefix, red(err.Error())))
+				}
+				send(fmt.Sprintf("%s %s", prefix, gray("log stream ended")))
+				select {
+				case ended <- name:
+				case <-done:
+				}
+			}(pod.Name)
+		}
+	}
+	follow(pods)
+
+	events, stop := t.kubectl.Watch("pod", rc.Spec.Selector)
+	defer stop()
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line := <-lines:
+			fmt.Fprintln(out, line)
+			continue
+		case name := <-ended:
+			// container may have restarted in the same pod.
+			streaming[name] = false
+		case _, ok := <-events:
+			if !ok {
+				// watch ended. fall back to polling.
+				events = nil
+			}
+		case <-ticker.C:
+		}
+		if pods, err = t.kubectl.PodList(rc.Spec.Selector); err != nil {
+			return
+		}
+		follow(pods)
+	}
+}
+
+// shouldFollow checks if logs of pod are to be followed. Pods are followed
+// once started, and again when their container is restarted.
+func shouldFollow(pod Pod, container string, followed map[string]int32) bool {
+	// logs are not available until containers are started.
+	if pod.Status.Phase == PodPending {
+		return false
+	}
+	restarts, ok := followed[pod.Name]
+	if !ok {
+		return true
+	}
+	cs, _ := containerStatus(pod, container)
+	return cs.State.Running != nil && cs.RestartCount > restarts
+}
+
+// streamLogs emits log lines of pod with prefix. Only lines matching re are
+// emitted when it is not nil, with matches highlighted.
+func (t *Tool) streamLogs(pod string, opts PodLogOptions, prefix string, re *regexp.Regexp, emit func(string)) (err error) {
+	stream, err := t.kubectl.Logs(pod, opts)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+	for scanner.Scan() {
+		if line, ok := grepLine(scanner.Text(), re); ok {
+			emit(prefix + " " + line)
+		}
+	}
+	return scanner.Err()
+}
+
+// grepLine highlights matches of re in line. ok is false when nothing matches.
+func grepLine(line string, re *regexp.Regexp) (highlighted string, ok bool) {
+	if re == nil {
+		return line, true
+	}
+	if !re.MatchString(line) {
+		return "", false
+	}
+	return re.ReplaceAllStringFunc(line, func(m string) string { return red(m) }), true
+}
@@ -0,0 +1,48 @@
+package kube
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodLogArgs(t *testing.T) {
+	since, tail := int64(600), int64(20)
+	assert.Equal(t, []string{"logs", "web-1"}, podLogArgs("web-1", PodLogOptions{}))
+	assert.Equal(t,
+		[]string{"logs", "web-1", "--container=web", "--follow", "--previous", "--since=600s", "--tail=20"},
+		podLogArgs("web-1", PodLogOptions{Container: "web", Follow: true, Previous: true, SinceSeconds: &since, TailLines: &tail}),
+	)
+}
+
+func TestGrepLine(t *testing.T) {
+	line, ok := grepLine("GET /health 200", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "GET /health 200", line)
+
+	re := regexp.MustCompile("5[0-9]{2}")
+	_, ok = grepLine("GET /health 200", re)
+	assert.False(t, ok)
+	line, ok = grepLine("GET /api 503", re)
+	assert.True(t, ok)
+	assert.Equal(t, "GET /api "+red("503"), line)
+}
+
+func TestShouldFollow(t *testing.T) {
+	running := ContainerState{Running: &ContainerStateRunning{}}
+	pod := testPod("web-1", "nginx:1.9.1", running, 0)
+	followed := map[string]int32{}
+	assert.True(t, shouldFollow(pod, "web", followed))
+
+	followed["web-1"] = 0
+	assert.False(t, shouldFollow(pod, "web", followed))
+	restarted := testPod("web-1", "nginx:1.9.1", running, 1)
+	assert.True(t, shouldFollow(restarted, "web", followed))
+	waiting := testPod("web-1", "nginx:1.9.1", ContainerState{Waiting: &ContainerStateWaiting{}}, 1)
+	assert.False(t, shouldFollow(waiting, "web", followed))
+
+	pending := testPod("web-2", "nginx:1.9.1", ContainerState{}, 0)
+	pending.Status.Phase = PodPending
+	assert.False(t, shouldFollow(pending, "web", followed))
+}
@@ -0,0 +1,313 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/buger/goterm"
+)
+
+// fieldDiff is a difference of single field between live RC and manifest.
+// Empty value means the field does not exist.
+type fieldDiff struct {
+	Path     string
+	Live     string
+	Manifest string
+}
+
+// Diff prints field level differences between live RC and manifest file.
+func (t *Tool) Diff(path string) (err error) {
+	t, local, err := t.manifestRC(path)
+	if err != nil {
+		return
+	}
+	live, err := t.kubectl.RC(local.Name)
+	if err != nil {
+		return
+	}
+	t.PrintContext()
+	log("rc      :", blue(local.Name))
+	diffs := rcDiffs(live, local)
+	if len(diffs) == 0 {
+		log(green("RC is in sync with manifest."))
+		return
+	}
+	printFieldDiffs(diffs)
+	return
+}
+
+// manifestRC reads RC from manifest file, and returns tool targeting
+// namespace of manifest when it has one.
+func (t *Tool) manifestRC(path string) (nt *Tool, rc ReplicationController, err error) {
+	if rc, err = t.kubectl.RCFromFile(path); err != nil {
+		return
+	}
+	ns := t.kubectl.Namespace
+	switch {
+	case rc.Namespace == "" || rc.Namespace == ns:
+		nt = t
+	case ns == "" || ns == "all":
+		nt = t.in(rc.Namespace)
+	default:
+		err = fmt.Errorf("namespace %s of %s does not match --namespace %s", rc.Namespace, path, ns)
+	}
+	return
+}
+
+// Sync patches fields of live RC which differ from manifest file,
+// and reloads pods when reload is set.
+func (t *Tool) Sync(path string, reload bool, one bool) (err error) {
+	t, local, err := t.manifestRC(path)
+	if err != nil {
+		return
+	}
+	live, err := t.kubectl.RC(local.Name)
+	if err != nil {
+		return
+	}
+	t.PrintContext()
+	log("rc      :", blue(local.Name))
+	diffs := rcDiffs(live, local)
+	if len(diffs) == 0 {
+		log(green("RC is in sync with manifest."))
+		return
+	}
+	printFieldDiffs(diffs)
+	t.confirm("continue?")
+
+	if err = t.patchRC(live.Name, syncPatch(live, local)); err != nil {
+		return
+	}
+	if reload {
+		err = t.Reload(live.Name, one)
+	}
+	return
+}
+
+func printFieldDiffs(diffs []fieldDiff) {
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "FIELD\tLIVE\tMANIFEST\n")
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", d.Path, red(orNone(d.Live)), green(orNone(d.Manifest)))
+	}
+	fmt.Fprint(out, w.String())
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
+
+// rcDiffs compares replicas, labels, container images, env and resources.
+func rcDiffs(live ReplicationController, local ReplicationController) (diffs []fieldDiff) {
+	if r1, r2 := replicas(live), replicas(local); r1 != r2 {
+		diffs = append(diffs, fieldDiff{"replicas", r1, r2})
+	}
+	diffs = append(diffs, mapDiffs("labels.", live.Labels, local.Labels)...)
+	diffs = append(diffs, mapDiffs("template.labels.", templateLabels(live), templateLabels(local))...)
+
+	liveCs, localCs := templateContainers(live), templateContainers(local)
+	for _, name := range containerNames(liveCs, localCs) {
+		lc, lok := findContainer(liveCs, name)
+		mc, mok := findContainer(localCs, name)
+		prefix := "containers[" + name + "]"
+		if !lok || !mok {
+			diffs = append(diffs, fieldDiff{prefix, presence(lok, lc.Image), presence(mok, mc.Image)})
+			continue
+		}
+		if lc.Image != mc.Image {
+			diffs = append(diffs, fieldDiff{prefix + ".image", lc.Image, mc.Image})
+		}
+		diffs = append(diffs, mapDiffs(prefix+".env.", envMap(lc.Env), envMap(mc.Env))...)
+		diffs = append(diffs, mapDiffs(prefix+".resources.requests.", resourceMap(lc.Resources.Requests), resourceMap(mc.Resources.Requests))...)
+		diffs = append(diffs, mapDiffs(prefix+".resources.limits.", resourceMap(lc.Resources.Limits), resourceMap(mc.Resources.Limits))...)
+	}
+	return
+}
+
+// syncPatch builds strategic merge patch to make live RC same as manifest
+// for fields compared in rcDiffs.
+func syncPatch(live ReplicationController, local ReplicationController) string {
+	spec := map[string]interface{}{}
+	patch := map[string]interface{}{"spec": spec}
+	if replicas(live) != replicas(local) && local.Spec.Replicas != nil {
+		spec["replicas"] = *local.Spec.Replicas
+	}
+	if p := mapPatch(live.Labels, local.Labels); len(p) > 0 {
+		patch["metadata"] = map[string]interface{}{"labels": p}
+	}
+	template := map[string]interface{}{}
+	if p := mapPatch(templateLabels(live), templateLabels(local)); len(p) > 0 {
+		template["metadata"] = map[string]interface{}{"labels": p}
+	}
+
+	containers := []interface{}{}
+	liveCs, localCs := templateContainers(live), templateContainers(local)
+	for _, name := range containerNames(liveCs, localCs) {
+		lc, lok := findContainer(liveCs, name)
+		mc, mok := findContainer(localCs, name)
+		switch {
+		case !mok:
+			containers = append(containers, map[string]interface{}{"name": name, "$patch": "delete"})
+			continue
+		case !lok:
+			containers = append(containers, mc)
+			continue
+		}
+		c := map[string]interface{}{}
+		if lc.Image != mc.Image {
+			c["image"] = mc.Image
+		}
+		if env := envPatch(lc.Env, mc.Env); len(env) > 0 {
+			c["env"] = env
+		}
+		res := map[string]interface{}{}
+		if p := mapPatch(resourceMap(lc.Resources.Requests), resourceMap(mc.Resources.Requests)); len(p) > 0 {
+			res["requests"] = p
+		}
+		if p := mapPatch(resourceMap(lc.Resources.Limits), resourceMap(mc.Resources.Limits)); len(p) > 0 {
+			res["limits"] = p
+		}
+		if len(res) > 0 {
+			c["resources"] = res
+		}
+		if len(c) > 0 {
+			c["name"] = name
+			containers = append(containers, c)
+		}
+	}
+	if len(containers) > 0 {
+		template["spec"] = map[string]interface{}{"containers": containers}
+	}
+	if len(template) > 0 {
+		spec["template"] = template
+	}
+	b, _ := json.Marshal(patch)
+	return string(b)
+}
+
+// envPatch returns changed env entries merged by name.
+func envPatch(live []EnvVar, local []EnvVar) (patch []map[string]interface{}) {
+	lm, mm := envMap(live), envMap(local)
+	for _, env := range live {
+		if _, ok := mm[env.Name]; !ok {
+			patch = append(patch, map[string]interface{}{"name": env.Name, "$patch": "delete"})
+		}
+	}
+	for _, env := range local {
+		if v, ok := lm[env.Name]; ok && v == mm[env.Name] {
+			continue
+		}
+		e := map[string]interface{}{"name": env.Name, "value": nil, "valueFrom": nil}
+		if env.ValueFrom != nil {
+			e["valueFrom"] = env.ValueFrom
+		} else {
+			e["value"] = env.Value
+		}
+		patch = append(patch, e)
+	}
+	return
+}
+
+// mapDiffs compares string maps with prefixing keys.
+func mapDiffs(prefix string, live map[string]string, local map[string]string) (diffs []fieldDiff) {
+	keys := map[string]string{}
+	for k, v := range live {
+		keys[k] = v
+	}
+	for k, v := range local {
+		keys[k] = v
+	}
+	for _, k := range sortedKeys(keys) {
+		if live[k] != local[k] {
+			diffs = append(diffs, fieldDiff{prefix + k, live[k], local[k]})
+		}
+	}
+	return
+}
+
+// mapPatch returns changed entries of local, and null for removed ones.
+func mapPatch(live map[string]string, local map[string]string) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k := range live {
+		if _, ok := local[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, v := range local {
+		if live[k] != v {
+			patch[k] = v
+		}
+	}
+	return patch
+}
+
+func replicas(rc ReplicationController) string {
+	if rc.Spec.Replicas == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*rc.Spec.Replicas))
+}
+
+func templateLabels(rc ReplicationController) map[string]string {
+	if rc.Spec.Template == nil {
+		return nil
+	}
+	return rc.Spec.Template.Labels
+}
+
+func templateContainers(rc ReplicationController) []Container {
+	if rc.Spec.Template == nil {
+		return nil
+	}
+	return rc.Spec.Template.Spec.Containers
+}
+
+func findContainer(cs []Container, name string) (Container, bool) {
+	for i := range cs {
+		if cs[i].Name == name {
+			return cs[i], true
+		}
+	}
+	return Container{}, false
+}
+
+// containerNames returns names of containers in live order followed by new ones.
+func containerNames(live []Container, local []Container) []string {
+	names := []string{}
+	for _, c := range live {
+		names = append(names, c.Name)
+	}
+	for _, c := range local {
+		if !contains(c.Name, names) {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func presence(ok bool, v string) string {
+	if !ok {
+		return ""
+	}
+	return v
+}
+
+func envMap(envs []EnvVar) map[string]string {
+	m := map[string]string{}
+	for _, env := range envs {
+		m[env.Name] = envValue(env)
+	}
+	return m
+}
+
+func resourceMap(rl ResourceList) map[string]string {
+	m := map[string]string{}
+	for k, v := range rl {
+		m[string(k)] = v
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+package kube
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testManifestRC(replicas int32, image string, env []EnvVar, labels map[string]string) ReplicationController {
+	rc := ReplicationController{}
+	rc.Name = "web"
+	rc.Labels = labels
+	rc.Spec.Replicas = &replicas
+	rc.Spec.Template = &PodTemplateSpec{}
+	rc.Spec.Template.Spec.Containers = []Container{{Name: "web", Image: image, Env: env}}
+	return rc
+}
+
+func TestRCDiffs(t *testing.T) {
+	live := testManifestRC(2, "nginx:1.9.1", []EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, map[string]string{"app": "web"})
+	local := testManifestRC(3, "nginx:1.9.2", []EnvVar{{Name: "A", Value: "1"}, {Name: "C", Value: "3"}}, map[string]string{"app": "web", "tier": "front"})
+	local.Spec.Template.Spec.Containers[0].Resources.Limits = ResourceList{"memory": "1Gi"}
+
+	diffs := rcDiffs(live, local)
+	paths := []string{}
+	for _, d := range diffs {
+		paths = append(paths, d.Path)
+	}
+	assert.Equal(t, []string{
+		"replicas",
+		"labels.tier",
+		"containers[web].image",
+		"containers[web].env.B",
+		"containers[web].env.C",
+		"containers[web].resources.limits.memory",
+	}, paths)
+	assert.Empty(t, rcDiffs(live, live))
+}
+
+func TestSyncPatch(t *testing.T) {
+	live := testManifestRC(2, "nginx:1.9.1", []EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, map[string]string{"app": "web", "old": "x"})
+	local := testManifestRC(2, "nginx:1.9.2", []EnvVar{{Name: "A", Value: "1"}}, map[string]string{"app": "web"})
+
+	patch := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(syncPatch(live, local)), &patch))
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"old": nil}},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "web",
+							"image": "nginx:1.9.2",
+							"env":   []interface{}{map[string]interface{}{"name": "B", "$patch": "delete"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, patch)
+}
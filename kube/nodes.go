@@ -0,0 +1,234 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/buger/goterm"
+)
+
+// zoneLabel is label of node which has name of availability zone.
+const zoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
+// NodeRow is a row of node listing.
+type NodeRow struct {
+	// Name of node.
+	Name string `json:"name"`
+	// Status is Ready, NotReady or Unknown.
+	Status string `json:"status"`
+	// Schedulable is false when node is cordoned.
+	Schedulable bool `json:"schedulable"`
+	// Pressure is conditions like MemoryPressure or DiskPressure which are true.
+	Pressure []string `json:"pressure"`
+	// Zone of node.
+	Zone string `json:"zone"`
+	// Capacity and allocatable quantities of resources.
+	CPUCapacity       string `json:"cpuCapacity"`
+	CPUAllocatable    string `json:"cpuAllocatable"`
+	MemoryCapacity    string `json:"memoryCapacity"`
+	MemoryAllocatable string `json:"memoryAllocatable"`
+	PodsCapacity      string `json:"podsCapacity"`
+	PodsAllocatable   string `json:"podsAllocatable"`
+	// Pods is number of pods scheduled to node in all namespaces.
+	Pods int `json:"pods"`
+}
+
+// PlacementRow is a row of pod placement of RC.
+type PlacementRow struct {
+	// Node name. Empty when pods are not scheduled yet.
+	Node string `json:"node"`
+	// Zone of node.
+	Zone string `json:"zone"`
+	// Pods is number of pods of RC on node.
+	Pods int `json:"pods"`
+	// Ready is number of available pods of RC on node.
+	Ready int `json:"ready"`
+}
+
+// PrintNodes prints conditions, resources and number of pods of nodes.
+func (t *Tool) PrintNodes() (err error) {
+	nodes, err := t.kubectl.NodeList()
+	if err != nil {
+		return
+	}
+	// pods are counted over all namespaces.
+	all := t.kubectl
+	all.Namespace = "all"
+	pods, err := all.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows := nodeRows(nodes, pods)
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NAME\tSTATUS\tPRESSURE\tCPU\tMEMORY\tPODS\tZONE\n")
+	for _, r := range rows {
+		status := r.Status
+		if !r.Schedulable {
+			status += ",SchedulingDisabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s/%s\t%d/%s\t%s\n",
+			r.Name, status, joinOrNone(r.Pressure),
+			orNone(r.CPUAllocatable), orNone(r.CPUCapacity),
+			orNone(r.MemoryAllocatable), orNone(r.MemoryCapacity),
+			r.Pods, orNone(r.PodsAllocatable), orNone(r.Zone),
+		)
+	}
+	fmt.Fprintln(out, colorRows(w.String(), func(i int) func(string, ...interface{}) string {
+		switch {
+		case rows[i].Status != "Ready":
+			return red
+		case len(rows[i].Pressure) > 0 || !rows[i].Schedulable:
+			return yellow
+		}
+		return nil
+	}))
+	return
+}
+
+// PrintPlacement prints how pods of RC are spread across nodes and zones.
+func (t *Tool) PrintPlacement(name string) (err error) {
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	nodes, err := t.kubectl.NodeList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(rc.Spec.Selector)
+	if err != nil {
+		return
+	}
+	rows := t.placementRows(nodes, pods)
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+	t.PrintContext()
+	log("rc      :", blue(name))
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NODE\tZONE\tPODS\tREADY\n")
+	zones := map[string]int{}
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", orNone(r.Node), orNone(r.Zone), r.Pods, r.Ready)
+		zones[r.Zone] += r.Pods
+	}
+	fmt.Fprintln(out, w.String())
+	for _, zone := range sortedZones(zones) {
+		log("zone", orNone(zone)+":", zones[zone], "pods")
+	}
+	for _, warning := range placementWarnings(rows, nodes) {
+		log(red("warning:"), warning)
+	}
+	return
+}
+
+// nodeRows makes rows of nodes with number of pods scheduled to them.
+func nodeRows(nodes []Node, pods []Pod) []NodeRow {
+	counts := map[string]int{}
+	for _, pod := range pods {
+		counts[pod.Spec.NodeName]++
+	}
+	rows := []NodeRow{}
+	for _, node := range nodes {
+		r := NodeRow{
+			Name:              node.Name,
+			Status:            "Unknown",
+			Schedulable:       !node.Spec.Unschedulable,
+			Pressure:          []string{},
+			Zone:              node.Labels[zoneLabel],
+			CPUCapacity:       node.Status.Capacity[ResourceCPU],
+			CPUAllocatable:    node.Status.Allocatable[ResourceCPU],
+			MemoryCapacity:    node.Status.Capacity[ResourceMemory],
+			MemoryAllocatable: node.Status.Allocatable[ResourceMemory],
+			PodsCapacity:      node.Status.Capacity[ResourcePods],
+			PodsAllocatable:   node.Status.Allocatable[ResourcePods],
+			Pods:              counts[node.Name],
+		}
+		for _, c := range node.Status.Conditions {
+			switch {
+			case c.Type == NodeReady && c.Status == ConditionTrue:
+				r.Status = "Ready"
+			case c.Type == NodeReady && c.Status == ConditionFalse:
+				r.Status = "NotReady"
+			case c.Type != NodeReady && c.Status == ConditionTrue:
+				r.Pressure = append(r.Pressure, string(c.Type))
+			}
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// placementRows counts pods of RC on each node.
+func (t *Tool) placementRows(nodes []Node, pods []Pod) []PlacementRow {
+	zones := map[string]string{}
+	for _, node := range nodes {
+		zones[node.Name] = node.Labels[zoneLabel]
+	}
+	index := map[string]int{}
+	rows := []PlacementRow{}
+	for _, pod := range pods {
+		name := pod.Spec.NodeName
+		i, ok := index[name]
+		if !ok {
+			i = len(rows)
+			index[name] = i
+			rows = append(rows, PlacementRow{Node: name, Zone: zones[name]})
+		}
+		rows[i].Pods++
+		if t.podAvailable(pod) {
+			rows[i].Ready++
+		}
+	}
+	sort.Sort(placementRowSorter(rows))
+	return rows
+}
+
+// placementWarnings flags pods of RC concentrated on single node or zone.
+func placementWarnings(rows []PlacementRow, nodes []Node) (warnings []string) {
+	total := 0
+	zones, clusterZones := map[string]bool{}, map[string]bool{}
+	for _, r := range rows {
+		total += r.Pods
+		zones[r.Zone] = true
+	}
+	for _, node := range nodes {
+		clusterZones[node.Labels[zoneLabel]] = true
+	}
+	if total < 2 {
+		return
+	}
+	if len(rows) == 1 && rows[0].Node != "" && len(nodes) > 1 {
+		warnings = append(warnings, fmt.Sprintf("all %d pods are on node %s", total, rows[0].Node))
+	}
+	if len(zones) == 1 && len(clusterZones) > 1 {
+		for zone := range zones {
+			warnings = append(warnings, fmt.Sprintf("all %d pods are in zone %s", total, orNone(zone)))
+		}
+	}
+	return
+}
+
+func sortedZones(zones map[string]int) []string {
+	list := []string{}
+	for zone := range zones {
+		list = append(list, zone)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// placementRowSorter sorts rows by number of pods, then node name.
+type placementRowSorter []PlacementRow
+
+func (s placementRowSorter) Len() int      { return len(s) }
+func (s placementRowSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s placementRowSorter) Less(i, j int) bool {
+	if s[i].Pods != s[j].Pods {
+		return s[i].Pods > s[j].Pods
+	}
+	return s[i].Node < s[j].Node
+}
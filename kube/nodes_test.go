@@ -0,0 +1,59 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeRows(t *testing.T) {
+	nodes := []Node{testNode("node-1", "a", ConditionTrue), testNode("node-2", "b", ConditionFalse)}
+	nodes[0].Status.Capacity = ResourceList{"cpu": "4", "memory": "16Gi", "pods": "110"}
+	nodes[0].Status.Allocatable = ResourceList{"cpu": "3800m", "memory": "15Gi", "pods": "110"}
+	nodes[1].Spec.Unschedulable = true
+	nodes[1].Status.Conditions = append(nodes[1].Status.Conditions, NodeCondition{Type: NodeMemoryPressure, Status: ConditionTrue})
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-2", "nginx:1.9.1", ContainerState{}, 0),
+	}
+
+	rows := nodeRows(nodes, pods)
+	require.Len(t, rows, 2)
+	assert.Equal(t, NodeRow{
+		Name: "node-1", Status: "Ready", Schedulable: true, Pressure: []string{}, Zone: "a",
+		CPUCapacity: "4", CPUAllocatable: "3800m", MemoryCapacity: "16Gi", MemoryAllocatable: "15Gi",
+		PodsCapacity: "110", PodsAllocatable: "110", Pods: 2,
+	}, rows[0])
+	assert.Equal(t, "NotReady", rows[1].Status)
+	assert.False(t, rows[1].Schedulable)
+	assert.Equal(t, []string{"MemoryPressure"}, rows[1].Pressure)
+	assert.Equal(t, 0, rows[1].Pods)
+}
+
+func TestPlacementRows(t *testing.T) {
+	tool := &Tool{}
+	nodes := []Node{testNode("node-1", "a", ConditionTrue), testNode("node-2", "b", ConditionTrue)}
+	running := ContainerState{Running: &ContainerStateRunning{}}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", running, 0),
+		testPod("web-2", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-3", "nginx:1.9.1", running, 0),
+	}
+	pods[2].Spec.NodeName = "node-2"
+
+	rows := tool.placementRows(nodes, pods)
+	assert.Equal(t, []PlacementRow{
+		{Node: "node-1", Zone: "a", Pods: 2, Ready: 1},
+		{Node: "node-2", Zone: "b", Pods: 1, Ready: 1},
+	}, rows)
+	assert.Empty(t, placementWarnings(rows, nodes))
+
+	// all pods on single node.
+	pods[2].Spec.NodeName = "node-1"
+	rows = tool.placementRows(nodes, pods)
+	assert.Equal(t, []string{"all 3 pods are on node node-1", "all 3 pods are in zone a"}, placementWarnings(rows, nodes))
+
+	// single replica is not flagged.
+	assert.Empty(t, placementWarnings(tool.placementRows(nodes, pods[:1]), nodes))
+}
@@ -0,0 +1,206 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/buger/goterm"
+)
+
+// Kinds of orphan report rows.
+const (
+	// OrphanPod is pod not matched by any RC nor owned by other controllers.
+	OrphanPod = "orphan"
+	// OrphanOverlap is pair of RCs whose selectors can match the same pod.
+	OrphanOverlap = "overlap"
+	// OrphanContested is pod matched by more than one RC.
+	OrphanContested = "contested"
+)
+
+// OrphanRow is a row of orphan report.
+type OrphanRow struct {
+	// Kind is orphan, overlap or contested.
+	Kind string `json:"kind"`
+	// Namespace of pod or RCs.
+	Namespace string `json:"namespace"`
+	// Pod name. Empty for overlap.
+	Pod string `json:"pod"`
+	// Phase of pod. Empty for overlap.
+	Phase string `json:"phase"`
+	// Age of pod like "5m". Empty for overlap.
+	Age string `json:"age"`
+	// RCs overlapping or matching pod.
+	RCs []string `json:"rcs"`
+}
+
+// PrintOrphans prints pods not matched by any RC, RCs with overlapping
+// selectors and pods matched by more than one RC. Orphan pods are deleted
+// after confirmation when cleanup is true.
+func (t *Tool) PrintOrphans(cleanup bool) (err error) {
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows := orphanRows(rcs, pods)
+	if t.output != OutputTable && t.output != OutputWide {
+		if err = writeRows(t.output, rows); err != nil || !cleanup {
+			return
+		}
+		return t.deleteOrphans(rows)
+	}
+
+	t.PrintContext()
+	sections := []struct {
+		kind, title, none string
+	}{
+		{OrphanPod, "orphan pods", "no orphan pods."},
+		{OrphanOverlap, "overlapping rc selectors", "no overlapping rc selectors."},
+		{OrphanContested, "pods matched by multiple rc", "no pods matched by multiple rc."},
+	}
+	for _, s := range sections {
+		w := goterm.NewTable(0, 4, 1, ' ', 0)
+		if s.kind == OrphanOverlap {
+			fmt.Fprintf(w, "NAMESPACE\tRCS\tSELECTORS\n")
+		} else {
+			fmt.Fprintf(w, "NAMESPACE\tPOD\tPHASE\tAGE\tRCS\n")
+		}
+		found := 0
+		for _, r := range rows {
+			if r.Kind != s.kind {
+				continue
+			}
+			found++
+			if s.kind == OrphanOverlap {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", r.Namespace, strings.Join(r.RCs, ","), rcSelectors(rcs, r))
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Namespace, r.Pod, orNone(r.Phase), orNone(r.Age), joinOrNone(r.RCs))
+		}
+		if found == 0 {
+			log(green(s.none))
+			continue
+		}
+		log(yellow("%s:", s.title))
+		fmt.Fprintln(out, w.String())
+	}
+	if cleanup {
+		err = t.deleteOrphans(rows)
+	}
+	return
+}
+
+// deleteOrphans deletes orphan pods after confirmation. Pods and RCs are
+// fetched again just before deletion so that pods adopted in the meantime are kept.
+func (t *Tool) deleteOrphans(rows []OrphanRow) (err error) {
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	targets := []OrphanRow{}
+	for _, r := range rows {
+		if r.Kind != OrphanPod {
+			continue
+		}
+		var pod Pod
+		if pod, err = t.in(r.Namespace).kubectl.Pod(r.Pod); err != nil {
+			return
+		}
+		if owners := matchingRCs(rcs, pod); len(owners) > 0 || controlled(pod) {
+			log(gray("pod %s is no longer orphan, skipped", r.Pod))
+			continue
+		}
+		targets = append(targets, r)
+	}
+	if len(targets) == 0 {
+		log(green("no orphan pods to delete."))
+		return
+	}
+	t.confirm(fmt.Sprintf("delete %d orphan pod(s)?", len(targets)))
+	for _, r := range targets {
+		logf("deleting pod %s... %s", red(r.Pod), gray("(%s)", r.Namespace))
+		if err = t.in(r.Namespace).kubectl.DeletePod(r.Pod); err != nil {
+			return
+		}
+	}
+	if t.dryRun {
+		log(yellow("[dry-run] no pods are deleted"))
+		return
+	}
+	log(green("done deleting orphan pods"))
+	return
+}
+
+// orphanRows returns orphan pods, overlapping RCs and contested pods in order.
+func orphanRows(rcs []ReplicationController, pods []Pod) []OrphanRow {
+	orphans, overlaps, contested := []OrphanRow{}, []OrphanRow{}, []OrphanRow{}
+	for _, pod := range pods {
+		owners := matchingRCs(rcs, pod)
+		r := OrphanRow{Namespace: pod.Namespace, Pod: pod.Name, Phase: string(pod.Status.Phase), Age: age(pod.CreationTimestamp), RCs: owners}
+		switch {
+		case len(owners) > 1:
+			r.Kind = OrphanContested
+			contested = append(contested, r)
+		case len(owners) == 0 && !controlled(pod):
+			r.Kind = OrphanPod
+			orphans = append(orphans, r)
+		}
+	}
+	for i := range rcs {
+		for j := i + 1; j < len(rcs); j++ {
+			if rcs[i].Namespace == rcs[j].Namespace && selectorsOverlap(rcs[i].Spec.Selector, rcs[j].Spec.Selector) {
+				names := []string{rcs[i].Name, rcs[j].Name}
+				sort.Strings(names)
+				overlaps = append(overlaps, OrphanRow{Kind: OrphanOverlap, Namespace: rcs[i].Namespace, RCs: names})
+			}
+		}
+	}
+	return append(append(orphans, overlaps...), contested...)
+}
+
+// matchingRCs returns names of RCs in namespace of pod whose selector matches it.
+func matchingRCs(rcs []ReplicationController, pod Pod) (names []string) {
+	names = []string{}
+	for _, rc := range rcs {
+		if rc.Namespace == pod.Namespace && Selector(rc.Spec.Selector).Matches(pod.Labels) {
+			names = append(names, rc.Name)
+		}
+	}
+	return
+}
+
+// controlled checks pod is owned by controller other than RC like DaemonSet or Job.
+func controlled(pod Pod) bool {
+	return len(pod.OwnerReferences) > 0 || pod.Annotations[createdByAnnotation] != "" || pod.Annotations[mirrorAnnotation] != ""
+}
+
+// selectorsOverlap checks a pod can be matched by both selectors,
+// that is no key has different values in them.
+func selectorsOverlap(a map[string]string, b map[string]string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; ok && bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// rcSelectors formats selectors of RCs in overlap row.
+func rcSelectors(rcs []ReplicationController, r OrphanRow) string {
+	selectors := []string{}
+	for _, name := range r.RCs {
+		for _, rc := range rcs {
+			if rc.Namespace == r.Namespace && rc.Name == name {
+				selectors = append(selectors, Selector(rc.Spec.Selector).Format())
+			}
+		}
+	}
+	return strings.Join(selectors, " / ")
+}
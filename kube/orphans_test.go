@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanRows(t *testing.T) {
+	rcs := []ReplicationController{{}, {}, {}}
+	for i, name := range []string{"web", "web-canary", "db"} {
+		rcs[i].Name, rcs[i].Namespace = name, "default"
+	}
+	rcs[0].Spec.Selector = map[string]string{"name": "web"}
+	rcs[1].Spec.Selector = map[string]string{"name": "web", "track": "canary"}
+	rcs[2].Spec.Selector = map[string]string{"name": "db"}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-canary-1", "nginx:1.9.2", ContainerState{}, 0),
+		testPod("debug", "busybox", ContainerState{}, 0),
+		testPod("job-1", "batch:1.0", ContainerState{}, 0),
+	}
+	pods[1].Labels = map[string]string{"name": "web", "track": "canary"}
+	pods[2].Labels = map[string]string{"run": "debug"}
+	pods[3].Labels = nil
+	pods[3].OwnerReferences = []OwnerReference{{Kind: "Job", Name: "job"}}
+
+	rows := orphanRows(rcs, pods)
+	require.Len(t, rows, 3)
+	assert.Equal(t, OrphanPod, rows[0].Kind)
+	assert.Equal(t, "debug", rows[0].Pod)
+	assert.Equal(t, OrphanRow{Kind: OrphanOverlap, Namespace: "default", RCs: []string{"web", "web-canary"}}, rows[1])
+	assert.Equal(t, OrphanContested, rows[2].Kind)
+	assert.Equal(t, "web-canary-1", rows[2].Pod)
+	assert.Equal(t, []string{"web", "web-canary"}, rows[2].RCs)
+}
+
+func TestSelectorsOverlap(t *testing.T) {
+	assert.True(t, selectorsOverlap(map[string]string{"a": "1"}, map[string]string{"a": "1", "b": "2"}))
+	assert.True(t, selectorsOverlap(map[string]string{"a": "1"}, map[string]string{"b": "2"}))
+	assert.False(t, selectorsOverlap(map[string]string{"a": "1"}, map[string]string{"a": "2", "b": "2"}))
+	assert.False(t, selectorsOverlap(nil, map[string]string{"a": "1"}))
+}
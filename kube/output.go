@@ -0,0 +1,307 @@
+package kube
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats of listings.
+const (
+	OutputTable = ""
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputCSV   = "csv"
+)
+
+// OutputFormats are available values for SetOutput.
+var OutputFormats = []string{OutputWide, OutputJSON, OutputYAML, OutputCSV}
+
+// RCRow is a row of RC listing. One row is made for each container of RC.
+type RCRow struct {
+	// Name of RC.
+	Name string `json:"name"`
+	// Namespace of RC.
+	Namespace string `json:"namespace"`
+	// Container name in pod template.
+	Container string `json:"container"`
+	// Replicas is desired number of pods.
+	Replicas int32 `json:"replicas"`
+	// Current is number of pods observed by RC.
+	Current int32 `json:"current"`
+	// Ready is number of available pods matching RC selector.
+	Ready int `json:"ready"`
+	// Image name without tag.
+	Image string `json:"image"`
+	// Version is tag of image, or digest when image is pinned without tag.
+	Version string `json:"version"`
+	// Age since RC is created like "3d".
+	Age string `json:"age"`
+	// Created is creation time of RC in RFC3339.
+	Created string `json:"created"`
+}
+
+// PodRow is a row of pod listing. One row is made for each container of pod.
+type PodRow struct {
+	// Name of pod.
+	Name string `json:"name"`
+	// Namespace of pod.
+	Namespace string `json:"namespace"`
+	// Container name.
+	Container string `json:"container"`
+	// Status of container. One of Running, Waiting or Terminated.
+	Status string `json:"status"`
+	// Ready is true when container passes readiness probe.
+	Ready bool `json:"ready"`
+	// Restarts is restart count of container.
+	Restarts int32 `json:"restarts"`
+	// IP of pod.
+	IP string `json:"ip"`
+	// NodeIP is IP of node which pod is running on.
+	NodeIP string `json:"nodeIP"`
+	// Node name which pod is scheduled to.
+	Node string `json:"node"`
+	// Image name without tag.
+	Image string `json:"image"`
+	// Version is tag of image, or digest when image is pinned without tag.
+	Version string `json:"version"`
+	// Digest of image which container is actually running.
+	Digest string `json:"digest"`
+	// Age since pod is created like "3d".
+	Age string `json:"age"`
+	// Created is creation time of pod in RFC3339.
+	Created string `json:"created"`
+}
+
+// rcRows makes rows of RCs. pods are used to count ready pods.
+func (t *Tool) rcRows(rcs []ReplicationController, pods []Pod) []RCRow {
+	rows := []RCRow{}
+	for _, rc := range rcs {
+		if rc.Spec.Template == nil {
+			continue
+		}
+		ready := 0
+		for i := range pods {
+			if pods[i].Namespace == rc.Namespace && Selector(rc.Spec.Selector).Matches(pods[i].Labels) && t.podAvailable(pods[i]) {
+				ready++
+			}
+		}
+		var replicas int32
+		if rc.Spec.Replicas != nil {
+			replicas = *rc.Spec.Replicas
+		}
+		for _, c := range rc.Spec.Template.Spec.Containers {
+			img, ver := parseImage(c.Image)
+			rows = append(rows, RCRow{
+				Name:      rc.Name,
+				Namespace: rc.Namespace,
+				Container: c.Name,
+				Replicas:  replicas,
+				Current:   rc.Status.Replicas,
+				Ready:     ready,
+				Image:     img,
+				Version:   ver,
+				Age:       age(rc.CreationTimestamp),
+				Created:   created(rc.CreationTimestamp),
+			})
+		}
+	}
+	return rows
+}
+
+// podRows makes rows of pods.
+func podRows(pods []Pod) []PodRow {
+	rows := []PodRow{}
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			cs, _ := containerStatus(pod, c.Name)
+			img, ver := parseImage(c.Image)
+			rows = append(rows, PodRow{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				Container: c.Name,
+				Status:    stateName(cs.State),
+				Ready:     cs.Ready,
+				Restarts:  cs.RestartCount,
+				IP:        pod.Status.PodIP,
+				NodeIP:    pod.Status.HostIP,
+				Node:      pod.Spec.NodeName,
+				Image:     img,
+				Version:   ver,
+				Digest:    imageIDDigest(cs.ImageID),
+				Age:       age(pod.CreationTimestamp),
+				Created:   created(pod.CreationTimestamp),
+			})
+		}
+	}
+	return rows
+}
+
+// containerStatus finds status of named container.
+func containerStatus(pod Pod, name string) (cs ContainerStatus, ok bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return
+}
+
+// stateName simplifies container state into Running, Waiting or Terminated.
+func stateName(state ContainerState) string {
+	switch {
+	case state.Terminated != nil:
+		return "Terminated"
+	case state.Running != nil:
+		return "Running"
+	}
+	return "Waiting"
+}
+
+func age(ts Time) string {
+	if ts.IsZero() {
+		return ""
+	}
+	return formatAge(time.Since(ts.Time))
+}
+
+func created(ts Time) string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.UTC().Format(time.RFC3339)
+}
+
+// formatAge formats duration in short form like "30s", "5m", "3h" or "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		if d < 0 {
+			d = 0
+		}
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+}
+
+// colorRows colors rows of aligned table below its header. color returns
+// color function of i-th row, or nil to leave it as is.
+func colorRows(table string, color func(i int) func(string, ...interface{}) string) string {
+	lines := strings.Split(table, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "" {
+			continue
+		}
+		if c := color(i - 1); c != nil {
+			lines[i] = c("%s", lines[i])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeRows writes slice of row structs in json, yaml or csv.
+// Field names of rows are kept stable for scripts.
+func writeRows(format string, rows interface{}) (err error) {
+	switch format {
+	case OutputJSON:
+		b, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(b))
+	case OutputYAML:
+		writeYAML(rows)
+	case OutputCSV:
+		err = writeCSV(rows)
+	default:
+		err = fmt.Errorf("unknown output format: %s", format)
+	}
+	return
+}
+
+// rowFields returns json field names of row struct type.
+func rowFields(typ reflect.Type) []string {
+	names := make([]string, typ.NumField())
+	for i := range names {
+		names[i] = strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+	}
+	return names
+}
+
+func writeCSV(rows interface{}) error {
+	v := reflect.ValueOf(rows)
+	w := csv.NewWriter(out)
+	w.Write(rowFields(v.Type().Elem()))
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		record := make([]string, row.NumField())
+		for j := range record {
+			record[j] = csvValue(row.Field(j).Interface())
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvValue formats value of cell. Lists are joined with comma.
+func csvValue(v interface{}) string {
+	if list, ok := v.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+func writeYAML(rows interface{}) {
+	v := reflect.ValueOf(rows)
+	if v.Len() == 0 {
+		fmt.Fprintln(out, "[]")
+		return
+	}
+	names := rowFields(v.Type().Elem())
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		for j, name := range names {
+			prefix := "  "
+			if j == 0 {
+				prefix = "- "
+			}
+			fmt.Fprintf(out, "%s%s: %s\n", prefix, name, yamlValue(row.Field(j).Interface()))
+		}
+	}
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_./@-][A-Za-z0-9_./@:+-]*$`)
+var yamlSpecial = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|null|~|[-+]?[0-9.]+(e[-+]?[0-9]+)?)$`)
+
+// yamlValue formats scalar value or flow sequence of strings.
+// Strings are double quoted when needed.
+func yamlValue(v interface{}) string {
+	if list, ok := v.([]string); ok {
+		values := make([]string, len(list))
+		for i := range list {
+			values[i] = yamlValue(list[i])
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if yamlPlain.MatchString(s) && !yamlSpecial.MatchString(s) && !strings.HasSuffix(s, ":") {
+		return s
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPod(name string, image string, state ContainerState, restarts int32) Pod {
+	pod := Pod{}
+	pod.Name = name
+	pod.Namespace = "default"
+	pod.Labels = map[string]string{"name": "web"}
+	pod.Spec.NodeName = "node-1"
+	pod.Spec.Containers = []Container{{Name: "web", Image: image}}
+	pod.Status.Phase = PodRunning
+	pod.Status.ContainerStatuses = []ContainerStatus{{
+		Name: "web", State: state, RestartCount: restarts,
+		Ready: state.Running != nil,
+	}}
+	return pod
+}
+
+func TestPodRows(t *testing.T) {
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{Running: &ContainerStateRunning{}}, 0),
+		testPod("web-2", "nginx:1.9.0", ContainerState{Waiting: &ContainerStateWaiting{}}, 3),
+	}
+	pods[1].Status.ContainerStatuses = nil
+	rows := podRows(pods)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Running", rows[0].Status)
+	assert.Equal(t, "1.9.1", rows[0].Version)
+	assert.Equal(t, "node-1", rows[0].Node)
+	assert.Equal(t, "Waiting", rows[1].Status)
+}
+
+func TestRCRows(t *testing.T) {
+	rc := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	rc.Namespace = "default"
+	rc.Spec.Selector = map[string]string{"name": "web"}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", ContainerState{Running: &ContainerStateRunning{}}, 0),
+		testPod("web-2", "nginx:1.9.1", ContainerState{Waiting: &ContainerStateWaiting{}}, 0),
+	}
+	kt := Tool{}
+	rows := kt.rcRows([]ReplicationController{rc}, pods)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0].Ready)
+	assert.Equal(t, int32(2), rows[0].Replicas)
+}
+
+func TestWriteRows(t *testing.T) {
+	b := bytes.Buffer{}
+	out = &b
+	rows := []RCRow{{Name: "web", Replicas: 2, Image: "nginx", Version: "1.10"}}
+
+	require.NoError(t, writeRows(OutputCSV, rows))
+	assert.Equal(t, "name,namespace,container,replicas,current,ready,image,version,age,created\nweb,,,2,0,0,nginx,1.10,,\n", b.String())
+
+	b.Reset()
+	require.NoError(t, writeRows(OutputYAML, rows))
+	assert.Contains(t, b.String(), "- name: web\n  namespace: \"\"\n")
+	assert.Contains(t, b.String(), "  version: \"1.10\"\n")
+
+	b.Reset()
+	require.NoError(t, writeRows(OutputJSON, []RCRow{}))
+	assert.Equal(t, "[]\n", b.String())
+
+	assert.Error(t, writeRows("xml", rows))
+}
+
+func TestColorRows(t *testing.T) {
+	colored := colorRows("NAME\nweb\ndb\n", func(i int) func(string, ...interface{}) string {
+		if i == 1 {
+			return red
+		}
+		return nil
+	})
+	assert.Equal(t, "NAME\nweb\n"+red("db")+"\n", colored)
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "0s", formatAge(-time.Second))
+	assert.Equal(t, "59s", formatAge(59*time.Second))
+	assert.Equal(t, "5m", formatAge(5*time.Minute))
+	assert.Equal(t, "3h", formatAge(3*time.Hour+5*time.Minute))
+	assert.Equal(t, "2d", formatAge(50*time.Hour))
+	assert.Equal(t, "1y", formatAge(400*24*time.Hour))
+}
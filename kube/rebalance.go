@@ -0,0 +1,282 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/buger/goterm"
+)
+
+// rebalanceRetries is number of replacements landing on the same node in a row
+// to give up rebalancing.
+const rebalanceRetries = 2
+
+// spread is number of pods of RC on schedulable nodes.
+type spread struct {
+	// nodes which are ready and schedulable, sorted by name.
+	nodes []string
+	// zones of nodes.
+	zones map[string]string
+	// count of pods on nodes.
+	count map[string]int
+}
+
+// Rebalance deletes pods of RC on overloaded nodes one by one, waiting for RC
+// to become available, until difference of pods between nodes and between
+// zones is within maxSkew.
+func (t *Tool) Rebalance(name string, maxSkew int) (err error) {
+	if maxSkew < 1 {
+		return errors.New("max skew must be 1 or more")
+	}
+	rc, err := t.kubectl.RC(name)
+	if err != nil {
+		return
+	}
+	nodes, err := t.kubectl.NodeList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(rc.Spec.Selector)
+	if err != nil {
+		return
+	}
+	s := newSpread(nodes, pods)
+	if len(s.nodes) == 0 {
+		return errors.New("no schedulable node found")
+	}
+	t.PrintContext()
+	log("rc      :", blue(name))
+	s.print()
+	moves, ok := s.plan(maxSkew)
+	if !ok {
+		return fmt.Errorf("skew of nodes and skew of zones cannot be both within %d", maxSkew)
+	}
+	if len(moves) == 0 {
+		log(green("pods are spread within skew %d", maxSkew))
+		return
+	}
+	t.confirm("continue?")
+
+	// each pod should not need to move more than once.
+	limit := 2 * int(*rc.Spec.Replicas)
+	deleted := []string{}
+	if t.dryRun {
+		for _, m := range moves {
+			victim := t.pickVictim(pods, m[0], deleted)
+			logf("%s delete pod %s on node %s, expected to be replaced on %s",
+				yellow("[dry-run]"), blue(victim), blue(m[0]), blue(m[1]))
+			deleted = append(deleted, victim)
+		}
+		log(yellow("[dry-run] no pods are deleted"))
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, pod := range pods {
+		seen[pod.Name] = true
+	}
+	stuck := 0
+	for i := 0; i < limit; i++ {
+		from, to := s.move(maxSkew)
+		if from == "" && s.excess(maxSkew) > 0 {
+			return fmt.Errorf("pods are not spread within skew %d, no move lowers skew", maxSkew)
+		}
+		if from == "" {
+			log(green("done rebalancing pods"))
+			return
+		}
+		victim := t.pickVictim(pods, from, deleted)
+		if victim == "" {
+			return fmt.Errorf("no pod to delete found on node %s", from)
+		}
+		logf("deleting pod %s on node %s... %s", blue(victim), blue(from), gray("(to %s)", to))
+		if err = t.kubectl.DeletePod(victim); err != nil {
+			return
+		}
+		deleted = append(deleted, victim)
+		if err = t.waitRCAvailable(name, deleted); err != nil {
+			return
+		}
+		if nodes, err = t.kubectl.NodeList(); err != nil {
+			return
+		}
+		if pods, err = t.kubectl.PodList(rc.Spec.Selector); err != nil {
+			return
+		}
+		s = newSpread(nodes, pods)
+
+		// scheduler may keep placing replacements on the same node.
+		landed := false
+		for _, pod := range pods {
+			if seen[pod.Name] || pod.Spec.NodeName == "" {
+				continue
+			}
+			seen[pod.Name] = true
+			landed = landed || pod.Spec.NodeName == from
+		}
+		if !landed {
+			stuck = 0
+			continue
+		}
+		stuck++
+		if stuck >= rebalanceRetries {
+			return fmt.Errorf("replacement pods keep landing on node %s", from)
+		}
+	}
+	return fmt.Errorf("pods are not spread within skew %d after %d deletions", maxSkew, limit)
+}
+
+// newSpread counts active pods on ready and schedulable nodes.
+func newSpread(nodes []Node, pods []Pod) spread {
+	s := spread{zones: map[string]string{}, count: map[string]int{}}
+	for _, r := range nodeRows(nodes, nil) {
+		if r.Status == "Ready" && r.Schedulable {
+			s.nodes = append(s.nodes, r.Name)
+			s.zones[r.Name] = r.Zone
+		}
+	}
+	sort.Strings(s.nodes)
+	for _, pod := range pods {
+		// terminating pods are already on the way out.
+		if _, ok := s.zones[pod.Spec.NodeName]; ok && podActive(pod) && pod.DeletionTimestamp == nil {
+			s.count[pod.Spec.NodeName]++
+		}
+	}
+	return s
+}
+
+// move returns node to delete pod from, and node expected to receive
+// replacement. Zones are balanced first. Only moves lowering excess skew are
+// returned, so that zone and node balancing never undo each other. from is
+// empty when no such move is left.
+func (s spread) move(maxSkew int) (from string, to string) {
+	excess := s.excess(maxSkew)
+	if excess == 0 {
+		return "", ""
+	}
+	zones := s.zoneCounts()
+	busy, idle := extremes(sortedZones(zones), zones)
+	from, _ = extremes(s.nodesIn(busy), s.count)
+	_, to = extremes(s.nodesIn(idle), s.count)
+	if busy != idle && s.moved(from, to).excess(maxSkew) < excess {
+		return
+	}
+	from, to = extremes(s.nodes, s.count)
+	if from != to && s.moved(from, to).excess(maxSkew) < excess {
+		return
+	}
+	return "", ""
+}
+
+// plan simulates moves until none is left. ok is false when skew stays over
+// maxSkew, as zones with uneven number of nodes may not allow both.
+func (s spread) plan(maxSkew int) (moves [][2]string, ok bool) {
+	for {
+		from, to := s.move(maxSkew)
+		if from == "" {
+			return moves, s.excess(maxSkew) == 0
+		}
+		moves = append(moves, [2]string{from, to})
+		s = s.moved(from, to)
+	}
+}
+
+// moved returns copy of spread with one pod moved.
+func (s spread) moved(from string, to string) spread {
+	count := map[string]int{}
+	for n, c := range s.count {
+		count[n] = c
+	}
+	count[from]--
+	count[to]++
+	s.count = count
+	return s
+}
+
+// excess is sum of skew of zones and of nodes exceeding maxSkew.
+func (s spread) excess(maxSkew int) (excess int) {
+	zones := s.zoneCounts()
+	busy, idle := extremes(sortedZones(zones), zones)
+	if d := zones[busy] - zones[idle] - maxSkew; d > 0 {
+		excess += d
+	}
+	most, least := extremes(s.nodes, s.count)
+	if d := s.count[most] - s.count[least] - maxSkew; d > 0 {
+		excess += d
+	}
+	return
+}
+
+func (s spread) zoneCounts() map[string]int {
+	counts := map[string]int{}
+	for _, n := range s.nodes {
+		counts[s.zones[n]] += s.count[n]
+	}
+	return counts
+}
+
+func (s spread) nodesIn(zone string) (nodes []string) {
+	for _, n := range s.nodes {
+		if s.zones[n] == zone {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+// print writes pods on each node, and per zone when nodes are in multiple zones.
+func (s spread) print() {
+	total := 0
+	for _, n := range s.nodes {
+		total += s.count[n]
+	}
+	zones := s.zoneCounts()
+	lower := total / len(s.nodes)
+	upper := lower
+	if total%len(s.nodes) != 0 {
+		upper++
+	}
+	logf("ideal   : %s pods per node over %d node(s) in %d zone(s)",
+		blue("%d-%d", lower, upper), len(s.nodes), len(zones))
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NODE\tZONE\tPODS\n")
+	for _, n := range s.nodes {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", n, orNone(s.zones[n]), s.count[n])
+	}
+	fmt.Fprintln(out, w.String())
+	if len(zones) > 1 {
+		for _, zone := range sortedZones(zones) {
+			log("zone", orNone(zone)+":", zones[zone], "pods")
+		}
+	}
+}
+
+// extremes returns keys with most and least count. Earlier keys win ties.
+func extremes(keys []string, counts map[string]int) (most string, least string) {
+	for i, k := range keys {
+		if i == 0 || counts[k] > counts[most] {
+			most = k
+		}
+		if i == 0 || counts[k] < counts[least] {
+			least = k
+		}
+	}
+	return
+}
+
+// pickVictim returns pod on node to delete. Unavailable pods are picked first.
+func (t *Tool) pickVictim(pods []Pod, node string, deleted []string) (name string) {
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node || contains(pod.Name, deleted) || !podActive(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if !t.podAvailable(pod) {
+			return pod.Name
+		}
+		if name == "" {
+			name = pod.Name
+		}
+	}
+	return
+}
@@ -0,0 +1,95 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpreadMove(t *testing.T) {
+	nodes := []Node{
+		testNode("node-1", "a", ConditionTrue),
+		testNode("node-2", "a", ConditionTrue),
+		testNode("node-3", "b", ConditionTrue),
+		testNode("node-4", "b", ConditionFalse),
+	}
+	pods := []Pod{}
+	for _, n := range []string{"node-1", "node-1", "node-1", "node-2", "node-4"} {
+		pod := testPod("web", "nginx:1.9.1", ContainerState{}, 0)
+		pod.Spec.NodeName = n
+		pods = append(pods, pod)
+	}
+
+	s := newSpread(nodes, pods)
+	assert.Equal(t, []string{"node-1", "node-2", "node-3"}, s.nodes)
+	assert.Equal(t, 0, s.count["node-4"])
+
+	// zone a has 4 pods and zone b has none.
+	from, to := s.move(1)
+	assert.Equal(t, "node-1", from)
+	assert.Equal(t, "node-3", to)
+	s.count["node-1"]--
+	s.count["node-3"]++
+
+	from, to = s.move(1)
+	assert.Equal(t, "node-1", from)
+	assert.Equal(t, "node-3", to)
+	s.count["node-1"]--
+	s.count["node-3"]++
+
+	// 1, 1, 2 pods on nodes and 2, 2 pods in zones.
+	from, _ = s.move(1)
+	assert.Empty(t, from)
+
+	// skew of nodes without zones.
+	s = newSpread(nodes[:2], pods)
+	from, to = s.move(1)
+	assert.Equal(t, "node-1", from)
+	assert.Equal(t, "node-2", to)
+	from, _ = s.move(2)
+	assert.Empty(t, from)
+}
+
+func TestSpreadPlanUnevenZones(t *testing.T) {
+	nodes := []Node{
+		testNode("a-1", "a", ConditionTrue),
+		testNode("a-2", "a", ConditionTrue),
+		testNode("a-3", "a", ConditionTrue),
+		testNode("b-1", "b", ConditionTrue),
+	}
+	pods := []Pod{}
+	for _, n := range []string{"a-1", "a-1", "a-2", "a-2", "a-3", "a-3", "b-1", "b-1"} {
+		pod := testPod("web", "nginx:1.9.1", ContainerState{}, 0)
+		pod.Spec.NodeName = n
+		pods = append(pods, pod)
+	}
+
+	// 4 pods in each zone puts 4 pods on b-1, so both skews cannot be 1.
+	s := newSpread(nodes, pods)
+	moves, ok := s.plan(1)
+	assert.False(t, ok)
+	assert.Equal(t, [][2]string{{"a-1", "b-1"}}, moves)
+	assert.Equal(t, 2, s.count["a-1"], "plan must not change spread")
+
+	// moves never undo each other.
+	s = s.moved("a-1", "b-1")
+	from, _ := s.move(1)
+	assert.Empty(t, from)
+
+	moves, ok = newSpread(nodes, pods).plan(3)
+	assert.True(t, ok)
+	assert.Equal(t, [][2]string{{"a-1", "b-1"}}, moves)
+}
+
+func TestPickVictim(t *testing.T) {
+	tool := &Tool{}
+	running := ContainerState{Running: &ContainerStateRunning{}}
+	pods := []Pod{
+		testPod("web-1", "nginx:1.9.1", running, 0),
+		testPod("web-2", "nginx:1.9.1", ContainerState{}, 0),
+		testPod("web-3", "nginx:1.9.1", running, 0),
+	}
+	assert.Equal(t, "web-2", tool.pickVictim(pods, "node-1", nil))
+	assert.Equal(t, "web-1", tool.pickVictim(pods, "node-1", []string{"web-2"}))
+	assert.Empty(t, tool.pickVictim(pods, "node-2", nil))
+}
@@ -0,0 +1,221 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/buger/goterm"
+)
+
+// Statuses of secret audit rows.
+const (
+	// SecretUsed is secret referenced by RC or pod.
+	SecretUsed = "used"
+	// SecretUnused is secret nobody references.
+	SecretUnused = "unused"
+	// SecretMissing is secret referenced but not existing.
+	SecretMissing = "missing"
+	// SecretServiceAccount is unreferenced token of service account.
+	// It is used by service account rather than pods.
+	SecretServiceAccount = "service-account"
+)
+
+// SecretRow is a row of secret audit. Values are only set when they are
+// requested, and masked unless unmasking is requested too.
+type SecretRow struct {
+	// Namespace of secret.
+	Namespace string `json:"namespace"`
+	// Name of secret.
+	Name string `json:"name"`
+	// Type of secret like Opaque. Empty when secret is missing.
+	Type string `json:"type"`
+	// Status is used, unused, missing or service-account.
+	Status string `json:"status"`
+	// Keys of secret data.
+	Keys []string `json:"keys"`
+	// RCs whose pod template references secret.
+	RCs []string `json:"rcs"`
+	// Pods referencing secret.
+	Pods []string `json:"pods"`
+	// Values of secret data.
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// AuditSecrets prints secrets with RCs and pods referencing them, flagging
+// unused secrets and references to missing ones. Values of secrets are never
+// printed unless showValues is set, and even then masked unless unmask is set.
+func (t *Tool) AuditSecrets(showValues bool, unmask bool) (err error) {
+	secrets, err := t.kubectl.SecretList()
+	if err != nil {
+		return
+	}
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows := secretRows(secrets, rcs, pods)
+	if showValues {
+		setSecretValues(rows, secrets, unmask)
+	}
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	if t.output == OutputWide {
+		fmt.Fprintf(w, "NAMESPACE\tNAME\tTYPE\tSTATUS\tKEYS\tRCS\tPODS\n")
+	} else {
+		fmt.Fprintf(w, "NAME\tTYPE\tSTATUS\tKEYS\tRCS\tPODS\n")
+	}
+	for _, r := range rows {
+		if t.output == OutputWide {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Namespace, r.Name, orNone(r.Type), r.Status, joinOrNone(r.Keys), joinOrNone(r.RCs), joinOrNone(r.Pods),
+			)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\n",
+			r.Name, orNone(r.Type), r.Status, len(r.Keys), joinOrNone(r.RCs), len(r.Pods),
+		)
+	}
+	fmt.Fprintln(out, colorRows(w.String(), func(i int) func(string, ...interface{}) string {
+		switch rows[i].Status {
+		case SecretMissing:
+			return red
+		case SecretUnused:
+			return yellow
+		}
+		return nil
+	}))
+
+	for _, r := range rows {
+		switch r.Status {
+		case SecretMissing:
+			log(red("warning:"), "secret", blue(r.Name), gray("(%s)", r.Namespace), "is referenced but does not exist")
+		case SecretUnused:
+			log(yellow("warning:"), "secret", blue(r.Name), gray("(%s)", r.Namespace), "is not referenced")
+		}
+	}
+	if !showValues {
+		return
+	}
+	for _, r := range rows {
+		if len(r.Values) == 0 {
+			continue
+		}
+		log(bold("secret %s", r.Name), gray("(%s)", r.Namespace))
+		for _, k := range r.Keys {
+			log(" ", k+":", r.Values[k])
+		}
+	}
+	return
+}
+
+// secretRows maps secrets to RCs and pods referencing them. Referenced but
+// missing secrets follow existing ones.
+func secretRows(secrets []Secret, rcs []ReplicationController, pods []Pod) []SecretRow {
+	rows := []SecretRow{}
+	index := map[string]int{}
+	for _, s := range secrets {
+		keys := []string{}
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		index[s.Namespace+"/"+s.Name] = len(rows)
+		rows = append(rows, SecretRow{
+			Namespace: s.Namespace, Name: s.Name, Type: string(s.Type),
+			Keys: keys, RCs: []string{}, Pods: []string{},
+		})
+	}
+	row := func(namespace string, name string) *SecretRow {
+		i, ok := index[namespace+"/"+name]
+		if !ok {
+			i = len(rows)
+			index[namespace+"/"+name] = i
+			rows = append(rows, SecretRow{
+				Namespace: namespace, Name: name, Status: SecretMissing,
+				Keys: []string{}, RCs: []string{}, Pods: []string{},
+			})
+		}
+		return &rows[i]
+	}
+	for _, rc := range rcs {
+		if rc.Spec.Template == nil {
+			continue
+		}
+		for _, name := range secretNames(rc.Spec.Template.Spec) {
+			r := row(rc.Namespace, name)
+			r.RCs = append(r.RCs, rc.Name)
+		}
+	}
+	for _, pod := range pods {
+		for _, name := range secretNames(pod.Spec) {
+			r := row(pod.Namespace, name)
+			r.Pods = append(r.Pods, pod.Name)
+		}
+	}
+	for i := range rows {
+		r := &rows[i]
+		switch {
+		case r.Status == SecretMissing:
+		case len(r.RCs) > 0 || len(r.Pods) > 0:
+			r.Status = SecretUsed
+		case r.Type == string(SecretTypeServiceAccountToken):
+			r.Status = SecretServiceAccount
+		default:
+			r.Status = SecretUnused
+		}
+	}
+	return rows
+}
+
+// secretNames returns names of secrets referenced by pod spec.
+func secretNames(spec PodSpec) []string {
+	names := map[string]bool{}
+	for _, v := range spec.Volumes {
+		if v.Secret != nil {
+			names[v.Secret.SecretName] = true
+		}
+	}
+	for _, c := range spec.Containers {
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				names[e.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+	for _, s := range spec.ImagePullSecrets {
+		names[s.Name] = true
+	}
+	return sortedSet(names)
+}
+
+// setSecretValues sets values of secrets to rows, masked unless unmask is set.
+func setSecretValues(rows []SecretRow, secrets []Secret, unmask bool) {
+	for _, s := range secrets {
+		for i := range rows {
+			if rows[i].Namespace != s.Namespace || rows[i].Name != s.Name {
+				continue
+			}
+			rows[i].Values = map[string]string{}
+			for k, v := range s.Data {
+				if unmask {
+					rows[i].Values[k] = string(v)
+				} else {
+					rows[i].Values[k] = maskValue(string(v))
+				}
+			}
+		}
+	}
+}
+
+// maskValue hides value entirely. Mask is fixed not to reveal any
+// character nor length of value.
+func maskValue(v string) string {
+	return "********"
+}
@@ -0,0 +1,49 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRows(t *testing.T) {
+	secrets := []Secret{
+		testSecret("db", SecretTypeOpaque, map[string][]byte{"user": []byte("web"), "password": []byte("secret")}),
+		testSecret("old", SecretTypeOpaque, nil),
+		testSecret("default-token-abc", SecretTypeServiceAccountToken, nil),
+	}
+	web := testManifestRC(2, "nginx:1.9.1", nil, nil)
+	web.Name, web.Namespace = "web", "default"
+	web.Spec.Template.Spec.Containers[0].Env = []EnvVar{
+		{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{SecretKeyRef: &SecretKeySelector{LocalObjectReference{Name: "db"}, "password"}}},
+	}
+	web.Spec.Template.Spec.ImagePullSecrets = []LocalObjectReference{{Name: "registry"}}
+	pod := testPod("web-1", "nginx:1.9.1", ContainerState{}, 0)
+	pod.Spec.Volumes = []Volume{{Name: "tls", VolumeSource: VolumeSource{Secret: &SecretVolumeSource{SecretName: "db"}}}}
+
+	rows := secretRows(secrets, []ReplicationController{web}, []Pod{pod})
+	require.Len(t, rows, 4)
+	assert.Equal(t, SecretRow{
+		Namespace: "default", Name: "db", Type: "Opaque", Status: SecretUsed,
+		Keys: []string{"password", "user"}, RCs: []string{"web"}, Pods: []string{"web-1"},
+	}, rows[0])
+	assert.Equal(t, SecretUnused, rows[1].Status)
+	assert.Equal(t, SecretServiceAccount, rows[2].Status)
+	assert.Equal(t, "registry", rows[3].Name)
+	assert.Equal(t, SecretMissing, rows[3].Status)
+	assert.Equal(t, []string{"web"}, rows[3].RCs)
+	assert.Empty(t, rows[3].Type)
+
+	setSecretValues(rows, secrets, false)
+	assert.Equal(t, map[string]string{"user": "********", "password": "********"}, rows[0].Values)
+	assert.Nil(t, rows[3].Values)
+	setSecretValues(rows, secrets, true)
+	assert.Equal(t, "secret", rows[0].Values["password"])
+}
+
+func TestMaskValue(t *testing.T) {
+	assert.Equal(t, "********", maskValue(""))
+	assert.Equal(t, "********", maskValue("password"))
+	assert.Equal(t, "********", maskValue("eyJhbGciOiJSUzI1NiJ9"))
+}
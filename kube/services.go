@@ -0,0 +1,210 @@
+package kube
+
+import (
+	"fmt"
+
+	"github.com/buger/goterm"
+)
+
+// SvcRow is a row of service listing.
+type SvcRow struct {
+	// Namespace of service.
+	Namespace string `json:"namespace"`
+	// Name of service.
+	Name string `json:"name"`
+	// Type like ClusterIP, NodePort or LoadBalancer.
+	Type string `json:"type"`
+	// ClusterIP of service.
+	ClusterIP string `json:"clusterIP"`
+	// Ports like 80:30080/TCP->8080 with node port and target port.
+	Ports []string `json:"ports"`
+	// Ready is number of ready endpoint addresses.
+	Ready int `json:"ready"`
+	// NotReady is number of not ready endpoint addresses.
+	NotReady int `json:"notReady"`
+	// RCs whose pod template matches selector of service.
+	RCs []string `json:"rcs"`
+	// Problems like selector matching no pods, or undeclared target port.
+	Problems []string `json:"problems"`
+}
+
+// PrintServices prints services with endpoint health and RCs behind them.
+// Services with problems and RCs not fronted by any service are flagged.
+func (t *Tool) PrintServices() (err error) {
+	svcs, err := t.kubectl.ServiceList()
+	if err != nil {
+		return
+	}
+	eps, err := t.kubectl.EndpointsList()
+	if err != nil {
+		return
+	}
+	rcs, err := t.kubectl.RCList()
+	if err != nil {
+		return
+	}
+	pods, err := t.kubectl.PodList(nil)
+	if err != nil {
+		return
+	}
+	rows, unfronted := serviceRows(svcs, eps, rcs, pods)
+	if t.output != OutputTable && t.output != OutputWide {
+		return writeRows(t.output, rows)
+	}
+
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	if t.output == OutputWide {
+		fmt.Fprintf(w, "NAMESPACE\tNAME\tTYPE\tCLUSTER-IP\tPORTS\tENDPOINTS\tRCS\tSELECTOR\n")
+	} else {
+		fmt.Fprintf(w, "NAME\tTYPE\tCLUSTER-IP\tPORTS\tENDPOINTS\tRCS\n")
+	}
+	for i, r := range rows {
+		endpoints := fmt.Sprintf("%d/%d", r.Ready, r.Ready+r.NotReady)
+		if t.output == OutputWide {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Namespace, r.Name, r.Type, orNone(r.ClusterIP), joinOrNone(r.Ports), endpoints, joinOrNone(r.RCs),
+				orNone(Selector(svcs[i].Spec.Selector).Format()),
+			)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, r.Type, orNone(r.ClusterIP), joinOrNone(r.Ports), endpoints, joinOrNone(r.RCs),
+		)
+	}
+	fmt.Fprintln(out, colorRows(w.String(), func(i int) func(string, ...interface{}) string {
+		switch {
+		case len(rows[i].Problems) > 0:
+			return red
+		case rows[i].NotReady > 0:
+			return yellow
+		}
+		return nil
+	}))
+	for _, r := range rows {
+		for _, p := range r.Problems {
+			log(red("warning:"), "svc", blue(r.Name), gray("(%s)", r.Namespace), p)
+		}
+	}
+	for _, rc := range unfronted {
+		log(yellow("warning:"), "rc", blue(rc.Name), gray("(%s)", rc.Namespace), "is not fronted by any service")
+	}
+	return
+}
+
+// serviceRows makes rows of services, and returns RCs not matched by any service.
+func serviceRows(svcs []Service, eps []Endpoints, rcs []ReplicationController, pods []Pod) (rows []SvcRow, unfronted []ReplicationController) {
+	rows = []SvcRow{}
+	fronted := map[string]bool{}
+	for _, svc := range svcs {
+		r := SvcRow{
+			Namespace: svc.Namespace,
+			Name:      svc.Name,
+			Type:      string(svc.Spec.Type),
+			ClusterIP: svc.Spec.ClusterIP,
+			Ports:     []string{},
+			RCs:       []string{},
+			Problems:  []string{},
+		}
+		for _, p := range svc.Spec.Ports {
+			r.Ports = append(r.Ports, servicePort(p))
+		}
+		for _, ep := range eps {
+			if ep.Namespace != svc.Namespace || ep.Name != svc.Name {
+				continue
+			}
+			for _, subset := range ep.Subsets {
+				r.Ready += len(subset.Addresses)
+				r.NotReady += len(subset.NotReadyAddresses)
+			}
+		}
+
+		// services without selector have endpoints managed manually.
+		selector := Selector(svc.Spec.Selector)
+		if len(selector) == 0 {
+			rows = append(rows, r)
+			continue
+		}
+		containers := []Container{}
+		for _, rc := range rcs {
+			if rc.Namespace == svc.Namespace && selector.Matches(rcPodLabels(rc)) {
+				r.RCs = append(r.RCs, rc.Name)
+				containers = append(containers, templateContainers(rc)...)
+				fronted[rc.Namespace+"/"+rc.Name] = true
+			}
+		}
+		matched := 0
+		for _, pod := range pods {
+			if pod.Namespace == svc.Namespace && selector.Matches(pod.Labels) {
+				matched++
+				containers = append(containers, pod.Spec.Containers...)
+			}
+		}
+		if matched == 0 {
+			r.Problems = append(r.Problems, "selector matches no pods")
+		}
+		if len(containers) > 0 {
+			for _, p := range svc.Spec.Ports {
+				target := targetPort(p)
+				if !declaresPort(containers, target) {
+					r.Problems = append(r.Problems, fmt.Sprintf("targetPort %s is not declared by containers", target.String()))
+				}
+			}
+		}
+		rows = append(rows, r)
+	}
+	for _, rc := range rcs {
+		if !fronted[rc.Namespace+"/"+rc.Name] {
+			unfronted = append(unfronted, rc)
+		}
+	}
+	return
+}
+
+// rcPodLabels returns labels of pods created by RC.
+// Selector is used for labels when template has none like kubernetes does.
+func rcPodLabels(rc ReplicationController) map[string]string {
+	if labels := templateLabels(rc); len(labels) > 0 {
+		return labels
+	}
+	return rc.Spec.Selector
+}
+
+// servicePort formats port like kubectl with target port.
+func servicePort(p ServicePort) string {
+	port := fmt.Sprintf("%d", p.Port)
+	if p.NodePort != 0 {
+		port += fmt.Sprintf(":%d", p.NodePort)
+	}
+	protocol := p.Protocol
+	if protocol == "" {
+		protocol = ProtocolTCP
+	}
+	target := targetPort(p)
+	return fmt.Sprintf("%s/%s->%s", port, protocol, target.String())
+}
+
+// targetPort returns target port of service port. Port is used when it is not set.
+func targetPort(p ServicePort) IntOrString {
+	if p.TargetPort.Type == String && p.TargetPort.StrVal != "" {
+		return p.TargetPort
+	}
+	if p.TargetPort.Type == Int && p.TargetPort.IntVal != 0 {
+		return p.TargetPort
+	}
+	return FromInt(int(p.Port))
+}
+
+// declaresPort checks any container declares port by number or name.
+func declaresPort(containers []Container, port IntOrString) bool {
+	for _, c := range containers {
+		for _, cp := range c.Ports {
+			if port.Type == String && cp.Name == port.StrVal {
+				return true
+			}
+			if port.Type == Int && cp.ContainerPort == port.IntVal {
+				return true
+			}
+		}
+	}
+	return false
+}
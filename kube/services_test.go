@@ -0,0 +1,42 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceRows(t *testing.T) {
+	web := testRC("web", 2, "nginx:1.9.1")
+	web.Spec.Template.Labels = map[string]string{"name": "web"}
+	web.Spec.Template.Spec.Containers[0].Ports = []ContainerPort{{Name: "http", ContainerPort: 8080}}
+	worker := testRC("worker", 1, "worker:1.0")
+	pods := []Pod{testPod("web-1", "nginx:1.9.1", ContainerState{}, 0)}
+	pods[0].Spec.Containers[0].Ports = []ContainerPort{{Name: "http", ContainerPort: 8080}}
+	svcs := []Service{
+		testService("web", map[string]string{"name": "web"}, FromString("http")),
+		testService("web-admin", map[string]string{"name": "web"}, FromInt(9090)),
+		testService("gone", map[string]string{"name": "gone"}, IntOrString{}),
+		testService("external", nil, IntOrString{}),
+	}
+	svcs[0].Spec.Ports[0].NodePort = 30080
+	eps := []Endpoints{{Subsets: []EndpointSubset{{
+		Addresses:         []EndpointAddress{{IP: "10.0.0.1"}},
+		NotReadyAddresses: []EndpointAddress{{IP: "10.0.0.2"}},
+	}}}}
+	eps[0].Name, eps[0].Namespace = "web", "default"
+
+	rows, unfronted := serviceRows(svcs, eps, []ReplicationController{web, worker}, pods)
+	require.Len(t, rows, 4)
+	assert.Equal(t, SvcRow{
+		Namespace: "default", Name: "web", Type: "ClusterIP", Ports: []string{"80:30080/TCP->http"},
+		Ready: 1, NotReady: 1, RCs: []string{"web"}, Problems: []string{},
+	}, rows[0])
+	assert.Equal(t, []string{"targetPort 9090 is not declared by containers"}, rows[1].Problems)
+	assert.Equal(t, []string{"80/TCP->80"}, rows[2].Ports)
+	assert.Equal(t, []string{"selector matches no pods"}, rows[2].Problems)
+	assert.Empty(t, rows[3].Problems)
+	require.Len(t, unfronted, 1)
+	assert.Equal(t, "worker", unfronted[0].Name)
+}
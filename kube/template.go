@@ -0,0 +1,278 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/abema/kubetool/registry"
+	"github.com/buger/goterm"
+)
+
+// ImageRef is parsed image used in templates.
+type ImageRef struct {
+	// Name of image without tag like "gcr.io/project/app".
+	Name string
+	// Registry host like "gcr.io". "registry-1.docker.io" for Docker Hub.
+	Registry string
+	// Repository path in registry like "project/app" or "library/nginx".
+	Repository string
+	// Tag of image. "latest" when tag and digest are omitted.
+	Tag string
+	// Digest of pinned image.
+	Digest string
+}
+
+// RCTemplateData is data of --template for RC listing.
+// Fields of ReplicationController like .Name or .Spec.Replicas are available.
+type RCTemplateData struct {
+	ReplicationController
+	// Image of first container.
+	Image ImageRef
+	// Images of all containers.
+	Images []ImageRef
+}
+
+// PodTemplateData is data of --template for pod listing.
+// Fields of Pod like .Name or .Spec.NodeName are available.
+type PodTemplateData struct {
+	Pod
+	// Image of first container.
+	Image ImageRef
+	// Images of all containers.
+	Images []ImageRef
+	// State of first container. One of Running, Waiting or Terminated.
+	// It is not named Status not to hide .Status of Pod.
+	State string
+	// Restarts is total restart count of containers.
+	Restarts int32
+}
+
+// templateFuncs are helper functions available in templates.
+var templateFuncs = template.FuncMap{
+	"image":  newImageRef,
+	"age":    age,
+	"color":  colorize,
+	"digest": shortDigest,
+	"join":   strings.Join,
+}
+
+func newImageRef(image string) ImageRef {
+	ref := registry.ParseReference(image)
+	name, _ := parseImage(image)
+	tag := ref.Tag
+	if tag == "" && ref.Digest == "" {
+		tag = "latest"
+	}
+	return ImageRef{
+		Name:       name,
+		Registry:   ref.Registry,
+		Repository: ref.Repository,
+		Tag:        tag,
+		Digest:     ref.Digest,
+	}
+}
+
+func containerImages(cs []Container) []ImageRef {
+	refs := make([]ImageRef, len(cs))
+	for i := range cs {
+		refs[i] = newImageRef(cs[i].Image)
+	}
+	return refs
+}
+
+func newRCTemplateData(rc ReplicationController) RCTemplateData {
+	data := RCTemplateData{ReplicationController: rc}
+	data.Images = containerImages(templateContainers(rc))
+	if len(data.Images) > 0 {
+		data.Image = data.Images[0]
+	}
+	return data
+}
+
+func newPodTemplateData(pod Pod) PodTemplateData {
+	data := PodTemplateData{Pod: pod}
+	data.Images = containerImages(pod.Spec.Containers)
+	if len(data.Images) > 0 {
+		data.Image = data.Images[0]
+		cs, _ := containerStatus(pod, pod.Spec.Containers[0].Name)
+		data.State = stateName(cs.State)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		data.Restarts += cs.RestartCount
+	}
+	return data
+}
+
+// colorize text with color name like "red" or "green".
+func colorize(name string, text interface{}) (string, error) {
+	colors := map[string]func(string, ...interface{}) string{
+		"red": red, "blue": blue, "green": green, "yellow": yellow, "magenta": magenta,
+		"cyan": cyan, "gray": gray, "white": white, "bold": bold,
+	}
+	f, ok := colors[name]
+	if !ok {
+		return "", fmt.Errorf("unknown color: %s", name)
+	}
+	// dereference pointer fields like .Spec.Replicas
+	if v := reflect.ValueOf(text); v.Kind() == reflect.Ptr && !v.IsNil() {
+		text = v.Elem().Interface()
+	}
+	return f("%v", text), nil
+}
+
+// printCustom writes objects with --template or --columns when specified.
+// data are passed to template and objs are used for columns.
+func (t *Tool) printCustom(objs []interface{}, data []interface{}) (printed bool, err error) {
+	switch {
+	case t.template != "":
+		return true, printTemplate(t.template, data)
+	case t.columns != "":
+		return true, printColumns(t.columns, objs)
+	}
+	return false, nil
+}
+
+// printTemplate executes template for each data.
+func printTemplate(text string, data []interface{}) (err error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return
+	}
+	for _, d := range data {
+		b := bytes.Buffer{}
+		if err = tmpl.Execute(&b, d); err != nil {
+			return
+		}
+		s := b.String()
+		if !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+		fmt.Fprint(out, s)
+	}
+	return
+}
+
+// column of --columns like NAME:.metadata.name.
+type column struct {
+	Header string
+	Path   string
+}
+
+func parseColumns(spec string) (cols []column, err error) {
+	for _, c := range strings.Split(spec, ",") {
+		colon := strings.IndexByte(c, ':')
+		if colon <= 0 || !strings.HasPrefix(c[colon+1:], ".") {
+			return nil, fmt.Errorf("invalid column %q: must be HEADER:.json.path", c)
+		}
+		cols = append(cols, column{c[:colon], c[colon+1:]})
+	}
+	if len(cols) == 0 {
+		err = errors.New("no columns specified")
+	}
+	return
+}
+
+// printColumns writes table of values picked by json path from objects.
+func printColumns(spec string, objs []interface{}) (err error) {
+	cols, err := parseColumns(spec)
+	if err != nil {
+		return
+	}
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	headers := make([]string, len(cols))
+	for i := range cols {
+		headers[i] = cols[i].Header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, obj := range objs {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		if err = json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		values := make([]string, len(cols))
+		for i := range cols {
+			values[i] = formatJSONValues(jsonPath(v, cols[i].Path))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	fmt.Fprintln(out, w.String())
+	return
+}
+
+// jsonPath picks values from decoded json by path like
+// ".spec.containers[0].image" or ".spec.containers[*].name".
+func jsonPath(v interface{}, path string) []interface{} {
+	values := []interface{}{v}
+	for _, key := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if key == "" {
+			continue
+		}
+		index := ""
+		if open := strings.IndexByte(key, '['); open >= 0 && strings.HasSuffix(key, "]") {
+			key, index = key[:open], key[open+1:len(key)-1]
+		}
+		next := []interface{}{}
+		for _, v := range values {
+			if key != "" {
+				m, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if v, ok = m[key]; !ok {
+					continue
+				}
+			}
+			if index == "" {
+				next = append(next, v)
+				continue
+			}
+			list, ok := v.([]interface{})
+			if !ok {
+				continue
+			}
+			if index == "*" {
+				next = append(next, list...)
+				continue
+			}
+			if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < len(list) {
+				next = append(next, list[i])
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// formatJSONValues joins values with comma. "<none>" when empty.
+func formatJSONValues(values []interface{}) string {
+	list := []string{}
+	for _, v := range values {
+		switch val := v.(type) {
+		case nil:
+			continue
+		case string:
+			list = append(list, val)
+		case float64:
+			list = append(list, strconv.FormatFloat(val, 'f', -1, 64))
+		case bool:
+			list = append(list, strconv.FormatBool(val))
+		default:
+			b, _ := json.Marshal(val)
+			list = append(list, string(b))
+		}
+	}
+	if len(list) == 0 {
+		return "<none>"
+	}
+	return strings.Join(list, ",")
+}
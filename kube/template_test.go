@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImageRef(t *testing.T) {
+	ref := newImageRef("gcr.io/project/app:v1")
+	assert.Equal(t, ImageRef{"gcr.io/project/app", "gcr.io", "project/app", "v1", ""}, ref)
+	assert.Equal(t, "latest", newImageRef("nginx").Tag)
+	assert.Equal(t, "", newImageRef("nginx@sha256:abc").Tag)
+}
+
+func TestPrintTemplate(t *testing.T) {
+	b := bytes.Buffer{}
+	out = &b
+	rc := testManifestRC(2, "gcr.io/project/app:v1", nil, nil)
+	data := []interface{}{newRCTemplateData(rc)}
+	require.NoError(t, printTemplate(`{{.Name}} {{.Image.Registry}} {{.Image.Tag}} {{color "bold" .Spec.Replicas}}`, data))
+	assert.Equal(t, "web gcr.io v1 "+bold("2")+"\n", b.String())
+
+	assert.Error(t, printTemplate(`{{color "pink" .Name}}`, data))
+
+	b.Reset()
+	pod := testPod("web-1", "nginx:1.9.1", ContainerState{Running: &ContainerStateRunning{}}, 2)
+	pod.Status.PodIP = "10.0.0.1"
+	require.NoError(t, printTemplate(`{{.Name}} {{.State}} {{.Restarts}} {{.Status.PodIP}}`, []interface{}{newPodTemplateData(pod)}))
+	assert.Equal(t, "web-1 Running 2 10.0.0.1\n", b.String())
+}
+
+func TestPrintColumns(t *testing.T) {
+	b := bytes.Buffer{}
+	out = &b
+	pod := testPod("web-1", "nginx:1.9.1", ContainerState{}, 2)
+	require.NoError(t, printColumns("NAME:.metadata.name,NODE:.spec.nodeName,R:.status.containerStatuses[*].restartCount,X:.spec.foo", []interface{}{pod}))
+	assert.Contains(t, b.String(), "NAME  NODE   R X")
+	assert.Contains(t, b.String(), "web-1 node-1 2 <none>")
+
+	assert.Error(t, printColumns("NAME", []interface{}{pod}))
+}
@@ -0,0 +1,476 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/buger/goterm"
+	"github.com/fatih/color"
+)
+
+// uiHelp is key bindings shown in dashboard.
+const uiHelp = "j/k:select  r:reload all  o:reload one  u:update  f:fix-version  q:quit"
+
+// uiEvents is number of recent events shown for selected RC.
+const uiEvents = 5
+
+// uiProgress is number of log lines of running operation shown in dashboard.
+const uiProgress = 10
+
+var reverse = color.New(color.ReverseVideo).SprintfFunc()
+
+// dashboard is state of full-screen UI.
+type dashboard struct {
+	t        *Tool
+	ctx      string
+	rcs      []ReplicationController
+	pods     []Pod
+	events   []Event
+	selected int
+	// err of last refresh.
+	err error
+	// status is message or prompt shown above key bindings.
+	status string
+	// prompt is an action waiting for confirmation.
+	prompt func()
+	// running is title of running operation.
+	running  string
+	progress *syncBuffer
+	done     chan error
+	// stdout is writer of logs while no operation is running.
+	stdout io.Writer
+	// stderr of kubectl, which would break screen if written to terminal.
+	stderr *syncBuffer
+	// cooked and raw switch terminal mode.
+	cooked func()
+	raw    func() error
+}
+
+// UI runs full-screen dashboard of RCs and their pods.
+// Operations are confirmed in dashboard, and their logs are shown as progress.
+func (t *Tool) UI() (err error) {
+	d := &dashboard{t: t, done: make(chan error, 1), stdout: out, stderr: &syncBuffer{}}
+	if d.ctx, err = t.kubectl.CurrentContext(); err != nil {
+		return
+	}
+	restore, err := rawTerminal()
+	if err != nil {
+		return
+	}
+	d.cooked, d.raw = restore, func() (err error) {
+		d.cooked, err = rawTerminal()
+		return
+	}
+	fmt.Print("\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Print("\033[?25h\033[?1049l")
+		d.cooked()
+	}()
+
+	// operations are confirmed in dashboard.
+	yes, stderr := t.yes, errOut
+	t.yes, errOut = true, d.stderr
+	defer func() { t.yes, out, errOut = yes, d.stdout, stderr }()
+
+	keys, next := make(chan string), make(chan bool, 1)
+	go readKeys(keys, next)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	// progress is redrawn frequently while operation is running.
+	redraw := time.NewTicker(500 * time.Millisecond)
+	defer redraw.Stop()
+
+	d.refresh()
+	waiting := false
+	for {
+		d.showStderr()
+		d.draw()
+		if !waiting {
+			next <- true
+			waiting = true
+		}
+		var progress <-chan time.Time
+		if d.running != "" {
+			progress = redraw.C
+		}
+		select {
+		case <-progress:
+		case key, ok := <-keys:
+			waiting = false
+			if !ok || d.handle(key) {
+				return
+			}
+		case err := <-d.done:
+			d.finish(err)
+		case <-ticker.C:
+			d.refresh()
+		case <-sig:
+			return
+		}
+	}
+}
+
+// rawTerminal disables line buffering and echo of terminal via stty.
+func rawTerminal() (restore func(), err error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, errors.New("ui requires terminal")
+	}
+	if _, err = stty("-icanon", "-echo", "min", "1"); err != nil {
+		return
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }, nil
+}
+
+func stty(args ...string) ([]byte, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Output()
+}
+
+// readKeys reads a key press each time next is sent,
+// so that stdin is left for version picker while not requested.
+func readKeys(keys chan<- string, next <-chan bool) {
+	b := make([]byte, 8)
+	for range next {
+		n, err := os.Stdin.Read(b)
+		if err != nil {
+			close(keys)
+			return
+		}
+		keys <- string(b[:n])
+	}
+}
+
+// handle key press. It returns true to quit.
+func (d *dashboard) handle(key string) (quit bool) {
+	if d.prompt != nil {
+		action := d.prompt
+		d.prompt, d.status = nil, ""
+		if key == "y" || key == "Y" {
+			action()
+		}
+		return
+	}
+	switch key {
+	case "j", "\033[B":
+		if d.selected < len(d.rcs)-1 {
+			d.selected++
+		}
+		return
+	case "k", "\033[A":
+		if d.selected > 0 {
+			d.selected--
+		}
+		return
+	case "q":
+		if d.running == "" {
+			return true
+		}
+	}
+	if len(d.rcs) == 0 || !strings.Contains("rouf", key) {
+		return
+	}
+	if d.running != "" {
+		d.status = yellow("%s is running", d.running)
+		return
+	}
+	name := d.rcs[d.selected].Name
+	switch key {
+	case "r":
+		d.confirm("reload all pods of "+name, func() error { return d.t.Reload(name, false) })
+	case "o":
+		d.confirm("reload 1 pod of "+name, func() error { return d.t.Reload(name, true) })
+	case "f":
+		d.confirm("fix version of "+name, func() error { return d.t.FixVersion(name) })
+	case "u":
+		version, err := d.pickVersion(d.rcs[d.selected])
+		if err != nil {
+			d.status = red(err.Error())
+			return
+		}
+		d.confirm("update "+name+" to "+version, func() error { return d.t.Update(name, "", version) })
+	}
+	return
+}
+
+// pickVersion runs version picker on normal terminal.
+func (d *dashboard) pickVersion(rc ReplicationController) (version string, err error) {
+	d.cooked()
+	fmt.Print("\033[?25h\033[2J\033[H")
+	log("update", blue(rc.Name))
+	version, err = d.t.selectVersion(rc, "")
+	fmt.Print("\033[?25l")
+	if rerr := d.raw(); rerr != nil && err == nil {
+		err = rerr
+	}
+	return
+}
+
+func (d *dashboard) confirm(title string, op func() error) {
+	d.status = bold("%s? (y/N)", title)
+	d.prompt = func() {
+		d.running, d.status = title, yellow("%s ...", title)
+		d.progress = &syncBuffer{}
+		out = d.progress
+		go func() { d.done <- op() }()
+	}
+}
+
+// finish running operation. Logs are kept until next operation.
+func (d *dashboard) finish(err error) {
+	out = d.stdout
+	if err != nil {
+		d.status = red("%s failed: %s", d.running, err)
+	} else {
+		d.status = green("%s done", d.running)
+	}
+	d.running = ""
+	d.refresh()
+}
+
+// showStderr shows last line of kubectl stderr written since last call
+// in status line. It is kept while prompt is shown.
+func (d *dashboard) showStderr() {
+	if d.prompt != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(d.stderr.take()), "\n")
+	if last := lines[len(lines)-1]; last != "" {
+		d.status = red("kubectl: %s", last)
+	}
+}
+
+func (d *dashboard) refresh() {
+	rcs, err := d.t.kubectl.RCList()
+	if err != nil {
+		d.err = err
+		return
+	}
+	pods, err := d.t.kubectl.PodList(nil)
+	if err != nil {
+		d.err = err
+		return
+	}
+	// events are optional.
+	events, _ := d.t.kubectl.EventList()
+	d.rcs, d.pods, d.events, d.err = rcs, pods, events, nil
+	if d.selected >= len(d.rcs) {
+		d.selected = len(d.rcs) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+}
+
+func (d *dashboard) draw() {
+	width, height := goterm.Width(), goterm.Height()
+	if width <= 0 || height <= 0 {
+		width, height = 120, 40
+	}
+	leftWidth := width * 2 / 5
+	left, right := d.rcPane(leftWidth), d.podPane(width-leftWidth-3)
+
+	bottom := []string{}
+	if d.progress != nil {
+		lines := strings.Split(strings.TrimRight(d.progress.String(), "\n"), "\n")
+		if len(lines) > uiProgress {
+			lines = lines[len(lines)-uiProgress:]
+		}
+		bottom = append(bottom, strings.Repeat("─", width))
+		bottom = append(bottom, lines...)
+	}
+	if d.err != nil {
+		bottom = append(bottom, red(d.err.Error()))
+	}
+	bottom = append(bottom, d.status, gray(uiHelp))
+
+	header := fmt.Sprintf("context: %s  namespace: %s  %s", yellow(d.ctx), cyan(orCurrent(d.t.kubectl.Namespace)), time.Now().Format("15:04:05"))
+	rows := height - len(bottom) - 3
+	goterm.Clear()
+	goterm.MoveCursor(1, 1)
+	goterm.Println(header)
+	goterm.Println()
+	for i := 0; i < rows && (i < len(left) || i < len(right)); i++ {
+		l, r := strings.Repeat(" ", leftWidth), ""
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		goterm.Println(l + gray(" │ ") + r)
+	}
+	for _, line := range bottom {
+		goterm.Println(line)
+	}
+	goterm.Flush()
+}
+
+// rcPane lists RCs with ready/desired counts and drift markers.
+func (d *dashboard) rcPane(width int) (lines []string) {
+	lines = append(lines, cell(bold("REPLICATION CONTROLLERS"), width, nil))
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "  NAME\tREADY\tVERSION\n")
+	drifts := make([]bool, len(d.rcs))
+	for i, rc := range d.rcs {
+		ready, drift := 0, 0
+		for _, pod := range d.pods {
+			if pod.Namespace != rc.Namespace || !Selector(rc.Spec.Selector).Matches(pod.Labels) {
+				continue
+			}
+			if d.t.podAvailable(pod) {
+				ready++
+			}
+			if outdated(rc, pod) {
+				drift++
+			}
+		}
+		marker := "  "
+		if drift > 0 {
+			marker, drifts[i] = "* ", true
+		}
+		ver := ""
+		if cs := templateContainers(rc); len(cs) > 0 {
+			_, ver = parseImage(cs[0].Image)
+		}
+		fmt.Fprintf(w, "%s%s\t%d/%s\t%s\n", marker, rc.Name, ready, replicas(rc), ver)
+	}
+	table := strings.Split(strings.TrimRight(w.String(), "\n"), "\n")
+	for i, line := range table {
+		f := (func(string, ...interface{}) string)(nil)
+		switch {
+		case i == 0:
+			f = gray
+		case i-1 == d.selected:
+			f = reverse
+		case drifts[i-1]:
+			f = yellow
+		}
+		lines = append(lines, cell(line, width, f))
+	}
+	return
+}
+
+// podPane lists pods and recent events of selected RC.
+func (d *dashboard) podPane(width int) (lines []string) {
+	if len(d.rcs) == 0 {
+		return []string{bold("PODS")}
+	}
+	rc := d.rcs[d.selected]
+	pods := rcPods(rc, d.pods)
+	lines = append(lines, bold("PODS of %s", rc.Name))
+	w := goterm.NewTable(0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "NAME\tSTATUS\tNODE\tR\tVERSION\n")
+	colors := []func(string, ...interface{}) string{gray}
+	for _, pod := range pods {
+		rows := podRows([]Pod{pod})
+		if len(rows) == 0 {
+			continue
+		}
+		var restarts int32
+		for _, r := range rows {
+			restarts += r.Restarts
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", pod.Name, rows[0].Status, pod.Spec.NodeName, restarts, rows[0].Version)
+		switch {
+		case !d.t.podAvailable(pod):
+			colors = append(colors, red)
+		case outdated(rc, pod):
+			colors = append(colors, yellow)
+		default:
+			colors = append(colors, nil)
+		}
+	}
+	for i, line := range strings.Split(strings.TrimRight(w.String(), "\n"), "\n") {
+		lines = append(lines, cell(line, width, colors[i]))
+	}
+
+	lines = append(lines, "", bold("EVENTS"))
+	events := rcEvents(rc, pods, d.events)
+	if len(events) > uiEvents {
+		events = events[len(events)-uiEvents:]
+	}
+	for _, e := range events {
+		f := (func(string, ...interface{}) string)(nil)
+		if e.Type == EventTypeWarning {
+			f = red
+		}
+		lines = append(lines, cell(fmt.Sprintf("%-4s %s %s: %s", age(e.LastTimestamp), e.InvolvedObject.Name, e.Reason, e.Message), width, f))
+	}
+	return
+}
+
+// rcPods returns pods matching RC selector.
+func rcPods(rc ReplicationController, pods []Pod) (list []Pod) {
+	for _, pod := range pods {
+		if pod.Namespace == rc.Namespace && Selector(rc.Spec.Selector).Matches(pod.Labels) {
+			list = append(list, pod)
+		}
+	}
+	return
+}
+
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;?]*[A-Za-z]")
+
+// cell truncates or pads text to width of terminal columns, then colors it.
+func cell(text string, width int, f func(string, ...interface{}) string) string {
+	plain := []rune(ansiEscape.ReplaceAllString(text, ""))
+	if len(plain) > width {
+		// colors of text are dropped when truncated.
+		text = string(plain[:width])
+	} else {
+		text += strings.Repeat(" ", width-len(plain))
+	}
+	if f == nil {
+		return text
+	}
+	return f("%s", text)
+}
+
+func orCurrent(ns string) string {
+	if ns == "" {
+		return "(current)"
+	}
+	return ns
+}
+
+// syncBuffer is a buffer written by running operation and read by dashboard.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// take returns written string and empties buffer.
+func (b *syncBuffer) take() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.buf.String()
+	b.buf.Reset()
+	return s
+}
@@ -0,0 +1,52 @@
+package kube
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCell(t *testing.T) {
+	assert.Equal(t, "abc  ", cell("abc", 5, nil))
+	assert.Equal(t, "abcde", cell("abcdefg", 5, nil))
+	assert.Equal(t, bold("abc")+"  ", cell(bold("abc"), 5, nil))
+	assert.Equal(t, red("ab"), cell("abc", 2, red))
+}
+
+func TestOutdated(t *testing.T) {
+	rc := testManifestRC(1, "nginx:1.9.1", nil, nil)
+	pod := Pod{}
+	pod.Spec.Containers = append([]Container{}, rc.Spec.Template.Spec.Containers...)
+	assert.False(t, outdated(rc, pod))
+	pod.Spec.Containers[0].Image = "nginx:0.0.1"
+	assert.True(t, outdated(rc, pod))
+	pod.Spec.Containers = nil
+	assert.True(t, outdated(rc, pod))
+}
+
+func TestDashboardSelect(t *testing.T) {
+	d := &dashboard{rcs: make([]ReplicationController, 2)}
+	d.handle("j")
+	d.handle("\033[B")
+	assert.Equal(t, 1, d.selected)
+	d.handle("k")
+	assert.Equal(t, 0, d.selected)
+	assert.True(t, d.handle("q"))
+	d.running = "reload"
+	assert.False(t, d.handle("q"))
+	d.handle("r")
+	assert.Nil(t, d.prompt)
+}
+
+func TestDashboardStderr(t *testing.T) {
+	d := &dashboard{stderr: &syncBuffer{}, status: "ready"}
+	d.showStderr()
+	assert.Equal(t, "ready", d.status)
+
+	fmt.Fprintln(d.stderr, "warning: deprecated")
+	fmt.Fprintln(d.stderr, "Error from server: pods \"web-1\" not found")
+	d.showStderr()
+	assert.Equal(t, red("kubectl: %s", `Error from server: pods "web-1" not found`), d.status)
+	assert.Empty(t, d.stderr.String())
+}
@@ -0,0 +1,209 @@
+package kube
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/goterm"
+)
+
+// watchInterval is interval of redrawing when no watch event comes.
+// It keeps age and ready counts fresh, and polls if watch is unavailable.
+var watchInterval = 2 * time.Second
+
+// Changes of rows highlighted in watch mode.
+const (
+	rowNew       = "new"
+	rowChanged   = "changed"
+	rowRestarted = "restarted"
+)
+
+var changeColors = map[string]func(string, ...interface{}) string{
+	rowNew:       green,
+	rowChanged:   yellow,
+	rowRestarted: red,
+}
+
+// WatchPodList redraws pod listing in place on every pod event.
+func (t *Tool) WatchPodList(rcname string) (err error) {
+	if err = t.watchable(); err != nil {
+		return
+	}
+	if err = t.filter.validate("pod"); err != nil {
+		return
+	}
+	var selector Selector
+	if rcname != "" {
+		selector = Selector{"name": rcname}
+	}
+	var prev map[string]PodRow
+	events, stop := t.kubectl.Watch("pod", selector)
+	defer stop()
+	return t.watch(events, func() (string, error) {
+		pods, err := t.kubectl.PodList(selector)
+		if err != nil {
+			return "", err
+		}
+		rows := t.filter.pods(podRows(pods))
+		changes := podChanges(prev, rows)
+		prev = map[string]PodRow{}
+		for _, r := range rows {
+			prev[podRowKey(r)] = r
+		}
+		return highlightRows(t.podTable(rows), changes), nil
+	})
+}
+
+// WatchRCList redraws RC listing in place on every RC or pod event.
+func (t *Tool) WatchRCList() (err error) {
+	if err = t.watchable(); err != nil {
+		return
+	}
+	if err = t.filter.validate("rc"); err != nil {
+		return
+	}
+	var prev map[string]RCRow
+	rcEvents, stopRC := t.kubectl.Watch("rc", nil)
+	defer stopRC()
+	podEvents, stopPod := t.kubectl.Watch("pod", nil)
+	defer stopPod()
+	return t.watch(mergeEvents(rcEvents, podEvents), func() (string, error) {
+		rcs, err := t.kubectl.RCList()
+		if err != nil {
+			return "", err
+		}
+		pods, err := t.kubectl.PodList(nil)
+		if err != nil {
+			return "", err
+		}
+		rows := t.filter.rcs(t.rcRows(rcs, pods))
+		changes := rcChanges(prev, rows)
+		prev = map[string]RCRow{}
+		for _, r := range rows {
+			prev[rcRowKey(r)] = r
+		}
+		return highlightRows(t.rcTable(rows), changes), nil
+	})
+}
+
+func (t *Tool) watchable() error {
+	if t.template != "" || t.columns != "" || (t.output != OutputTable && t.output != OutputWide) {
+		return errors.New("watch supports only table and wide output")
+	}
+	return nil
+}
+
+// watch redraws screen with header and table made by draw
+// on each event, or every watchInterval. It returns only on error.
+func (t *Tool) watch(events <-chan struct{}, draw func() (string, error)) (err error) {
+	ctx, err := t.kubectl.CurrentContext()
+	if err != nil {
+		return
+	}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		table, err := draw()
+		if err != nil {
+			return err
+		}
+		goterm.Clear()
+		goterm.MoveCursor(1, 1)
+		goterm.Printf("context: %s  namespace: %s  %s\n\n", yellow(ctx), cyan(orCurrent(t.kubectl.Namespace)), time.Now().Format("2006-01-02 15:04:05"))
+		goterm.Println(table)
+		goterm.Println(green(rowNew), yellow(rowChanged), red(rowRestarted), gray(" Ctrl-C to quit"))
+		goterm.Flush()
+
+		select {
+		case _, ok := <-events:
+			if !ok {
+				// watch ended. fall back to polling.
+				events = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// mergeEvents merges event channels into one which is closed when all are closed.
+func mergeEvents(chs ...<-chan struct{}) <-chan struct{} {
+	merged := make(chan struct{}, 1)
+	wg := sync.WaitGroup{}
+	for _, ch := range chs {
+		wg.Add(1)
+		go func(ch <-chan struct{}) {
+			defer wg.Done()
+			for range ch {
+				select {
+				case merged <- struct{}{}:
+				default:
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	return merged
+}
+
+func podRowKey(r PodRow) string {
+	return r.Namespace + "/" + r.Name + "/" + r.Container
+}
+
+func rcRowKey(r RCRow) string {
+	return r.Namespace + "/" + r.Name + "/" + r.Container
+}
+
+// podChanges compares rows with previous ones. Nothing is changed on first draw.
+func podChanges(prev map[string]PodRow, rows []PodRow) []string {
+	changes := make([]string, len(rows))
+	if prev == nil {
+		return changes
+	}
+	for i, r := range rows {
+		p, ok := prev[podRowKey(r)]
+		switch {
+		case !ok:
+			changes[i] = rowNew
+		case r.Restarts > p.Restarts:
+			changes[i] = rowRestarted
+		case r.Status != p.Status || r.Ready != p.Ready || r.Image != p.Image || r.Version != p.Version:
+			changes[i] = rowChanged
+		}
+	}
+	return changes
+}
+
+// rcChanges compares rows with previous ones. Nothing is changed on first draw.
+func rcChanges(prev map[string]RCRow, rows []RCRow) []string {
+	changes := make([]string, len(rows))
+	if prev == nil {
+		return changes
+	}
+	for i, r := range rows {
+		p, ok := prev[rcRowKey(r)]
+		switch {
+		case !ok:
+			changes[i] = rowNew
+		case r.Replicas != p.Replicas || r.Current != p.Current || r.Ready != p.Ready || r.Image != p.Image || r.Version != p.Version:
+			changes[i] = rowChanged
+		}
+	}
+	return changes
+}
+
+// highlightRows colors lines of formatted table. First line is header.
+// Colors are applied after alignment not to break column widths.
+func highlightRows(table string, changes []string) string {
+	lines := strings.Split(table, "\n")
+	for i, change := range changes {
+		if f, ok := changeColors[change]; ok && i+1 < len(lines) {
+			lines[i+1] = f("%s", lines[i+1])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
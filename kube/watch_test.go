@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodChanges(t *testing.T) {
+	rows := testPodRows()
+	assert.Equal(t, []string{"", "", ""}, podChanges(nil, rows))
+
+	prev := map[string]PodRow{}
+	for _, r := range rows[:2] {
+		prev[podRowKey(r)] = r
+	}
+	rows[0].Restarts++
+	rows[1].Status = "Running"
+	assert.Equal(t, []string{rowRestarted, rowChanged, rowNew}, podChanges(prev, rows))
+}
+
+func TestRCChanges(t *testing.T) {
+	rows := []RCRow{{Name: "web", Replicas: 3, Ready: 2}, {Name: "db", Replicas: 1, Ready: 1}}
+	prev := map[string]RCRow{rcRowKey(rows[0]): rows[0], rcRowKey(rows[1]): rows[1]}
+	rows[0].Ready = 3
+	assert.Equal(t, []string{rowChanged, ""}, rcChanges(prev, rows))
+}
+
+func TestHighlightRows(t *testing.T) {
+	table := "NAME STATUS\nweb-1 Running\nweb-2 Waiting\n"
+	lines := strings.Split(highlightRows(table, []string{"", rowNew}), "\n")
+	assert.Equal(t, "web-1 Running", lines[1])
+	assert.Equal(t, green("web-2 Waiting"), lines[2])
+}
@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDockerConfig returns path of docker client config file.
+// $DOCKER_CONFIG is used when defined.
+func DefaultDockerConfig() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".docker", "config.json")
+}
+
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// DockerConfigAuth loads credentials stored by `docker login` from config
+// file and returns function for Client.Auth.
+// Credential helpers (credsStore) are not supported.
+func DockerConfigAuth(path string) (auth func(host string) (string, string), err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	conf := dockerConfig{}
+	if err = json.Unmarshal(b, &conf); err != nil {
+		return
+	}
+	creds := map[string][2]string{}
+	for key, a := range conf.Auths {
+		user, pass := a.Username, a.Password
+		if a.Auth != "" {
+			dec, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return nil, err
+			}
+			if colon := strings.IndexByte(string(dec), ':'); colon >= 0 {
+				user, pass = string(dec[:colon]), string(dec[colon+1:])
+			}
+		}
+		creds[configHost(key)] = [2]string{user, pass}
+	}
+	return func(host string) (string, string) {
+		c := creds[configHost(host)]
+		return c[0], c[1]
+	}, nil
+}
+
+// configHost normalizes config key like "https://index.docker.io/v1/" into
+// registry host.
+func configHost(key string) string {
+	if i := strings.Index(key, "://"); i >= 0 {
+		key = key[i+3:]
+	}
+	if slash := strings.IndexByte(key, '/'); slash >= 0 {
+		key = key[:slash]
+	}
+	switch key {
+	case dockerHub, "index." + dockerHub, "registry." + dockerHub:
+		return DefaultRegistry
+	}
+	return key
+}
@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerConfigAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubetool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	conf := `{"auths":{
+		"https://index.docker.io/v1/":{"auth":"aHViOnNlY3JldA=="},
+		"localhost:5000":{"username":"local","password":"pass"}
+	}}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(conf), 0600))
+
+	auth, err := DockerConfigAuth(path)
+	require.NoError(t, err)
+
+	user, pass := auth(DefaultRegistry)
+	assert.Equal(t, "hub", user)
+	assert.Equal(t, "secret", pass)
+
+	user, pass = auth("localhost:5000")
+	assert.Equal(t, "local", user)
+	assert.Equal(t, "pass", pass)
+
+	user, _ = auth("gcr.io")
+	assert.Equal(t, "", user)
+}
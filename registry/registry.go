@@ -0,0 +1,342 @@
+// Package registry is a minimal Docker Registry v2 API client used by
+// kubetool to resolve image tags.
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the host used for images without registry part.
+	DefaultRegistry = "registry-1.docker.io"
+
+	dockerHub = "docker.io"
+)
+
+// manifestTypes are accepted manifest media types to resolve digests.
+// Schema1 is not accepted, since digest of signed manifest is not hash of
+// its body.
+var manifestTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Reference of image in registry.
+type Reference struct {
+	// Registry host (with port) like "gcr.io" or "localhost:5000".
+	Registry string
+	// Repository path like "library/nginx".
+	Repository string
+	// Tag of image, empty when not specified.
+	Tag string
+	// Digest of image like "sha256:...", empty when not specified.
+	Digest string
+}
+
+// ParseReference parses image string such as "nginx:1.9.1",
+// "gcr.io/project/app:v1" or "app@sha256:...".
+func ParseReference(image string) (ref Reference) {
+	name := image
+	if at := strings.IndexByte(name, '@'); at >= 0 {
+		ref.Digest = name[at+1:]
+		name = name[:at]
+	}
+	if colon := strings.LastIndexByte(name, ':'); colon > strings.LastIndexByte(name, '/') {
+		ref.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+	ref.Registry = DefaultRegistry
+	if slash := strings.IndexByte(name, '/'); slash > 0 {
+		host := name[:slash]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[slash+1:]
+		}
+	}
+	if ref.Registry == dockerHub || ref.Registry == "index."+dockerHub {
+		ref.Registry = DefaultRegistry
+	}
+	if ref.Registry == DefaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return
+}
+
+// Reference returns tag or digest to request manifest.
+// "latest" is used when both are empty.
+func (r Reference) Reference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	if r.Tag != "" {
+		return r.Tag
+	}
+	return "latest"
+}
+
+// Client requests Docker Registry v2 API.
+// Zero value is usable and requests registries anonymously via https.
+type Client struct {
+	// HTTPClient to send requests. http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+	// Insecure uses plain http instead of https.
+	Insecure bool
+	// Auth returns credentials of registry host. Empty username means anonymous.
+	Auth func(host string) (username string, password string)
+}
+
+// Digest resolves tag of reference to manifest digest.
+func (c *Client) Digest(ref Reference) (digest string, err error) {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, ref.Reference())
+	header := http.Header{"Accept": {strings.Join(manifestTypes, ", ")}}
+
+	res, err := c.do("HEAD", ref, path, header)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("manifest %s:%s: %s", ref.Repository, ref.Reference(), res.Status)
+	}
+	if digest = res.Header.Get("Docker-Content-Digest"); digest != "" {
+		return
+	}
+
+	// some registries do not return digest header on HEAD request.
+	res, err = c.do("GET", ref, path, header)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	// error pages must not be hashed as manifest.
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("manifest %s:%s: %s", ref.Repository, ref.Reference(), res.Status)
+	}
+	if digest = res.Header.Get("Docker-Content-Digest"); digest != "" {
+		return
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+	// registries without schema2 still return schema1 regardless of Accept.
+	manifest := struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}{}
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return "", fmt.Errorf("manifest %s:%s: %s", ref.Repository, ref.Reference(), err)
+	}
+	if manifest.SchemaVersion != 2 {
+		return "", fmt.Errorf("manifest %s:%s: digest of schema version %d is not supported", ref.Repository, ref.Reference(), manifest.SchemaVersion)
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Tags lists all tags of repository following pagination.
+func (c *Client) Tags(ref Reference) (tags []string, err error) {
+	path := fmt.Sprintf("/v2/%s/tags/list", ref.Repository)
+	for path != "" {
+		var res *http.Response
+		res, err = c.do("GET", ref, path, nil)
+		if err != nil {
+			return
+		}
+		body := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("tags of %s: %s", ref.Repository, res.Status)
+		}
+		if err != nil {
+			return
+		}
+		tags = append(tags, body.Tags...)
+		path = nextLink(res.Header.Get("Link"))
+	}
+	return
+}
+
+// nextLink returns path of `Link: </v2/...?last=x&n=y>; rel="next"` header.
+func nextLink(link string) string {
+	if !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.IndexByte(link, '<'), strings.IndexByte(link, '>')
+	if start < 0 || end < start {
+		return ""
+	}
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
+
+// do sends request to registry with handling auth challenge.
+func (c *Client) do(method string, ref Reference, path string, header http.Header) (res *http.Response, err error) {
+	u := c.baseURL(ref.Registry) + path
+	res, err = c.send(method, u, header, "")
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return
+	}
+	res.Body.Close()
+
+	scheme, params := parseChallenge(res.Header.Get("WWW-Authenticate"))
+	user, pass := c.credentials(ref.Registry)
+	authorization := ""
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		var token string
+		token, err = c.token(params, ref, user, pass)
+		if err != nil {
+			return
+		}
+		authorization = "Bearer " + token
+	case "basic":
+		if user == "" {
+			return nil, fmt.Errorf("registry %s requires credentials", ref.Registry)
+		}
+		req, _ := http.NewRequest("GET", u, nil)
+		req.SetBasicAuth(user, pass)
+		authorization = req.Header.Get("Authorization")
+	default:
+		return nil, fmt.Errorf("unsupported auth challenge from %s: %q", ref.Registry, scheme)
+	}
+	return c.send(method, u, header, authorization)
+}
+
+func (c *Client) send(method string, u string, header http.Header, authorization string) (res *http.Response, err error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.httpClient().Do(req)
+}
+
+// token fetches bearer token from auth server described in challenge.
+func (c *Client) token(params map[string]string, ref Reference, user string, pass string) (token string, err error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", errors.New("bearer challenge without realm")
+	}
+	q := url.Values{}
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.Repository + ":pull"
+	}
+	q.Set("scope", scope)
+	req, err := http.NewRequest("GET", realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return
+	}
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s: %s", realm, res.Status)
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err = json.Unmarshal(b, &body); err != nil {
+		return
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("empty token from %s", realm)
+}
+
+func (c *Client) credentials(host string) (user string, pass string) {
+	if c.Auth == nil {
+		return
+	}
+	return c.Auth(host)
+}
+
+func (c *Client) baseURL(host string) string {
+	if c.Insecure {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge parses WWW-Authenticate header value like
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`.
+func parseChallenge(header string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+	header = strings.TrimSpace(header)
+	sp := strings.IndexByte(header, ' ')
+	if sp < 0 {
+		return header, params
+	}
+	scheme = header[:sp]
+	rest := header[sp+1:]
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimSpace(rest[eq+1:])
+		val := ""
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				val, rest = rest[1:], ""
+			} else {
+				val, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.IndexByte(rest, ','); comma >= 0 {
+			val, rest = rest[:comma], rest[comma:]
+		} else {
+			val, rest = rest, ""
+		}
+		params[key] = val
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return
+}
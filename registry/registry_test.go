@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := map[string]Reference{
+		"nginx":                     {DefaultRegistry, "library/nginx", "", ""},
+		"nginx:1.9.1":               {DefaultRegistry, "library/nginx", "1.9.1", ""},
+		"abema/nginx:latest":        {DefaultRegistry, "abema/nginx", "latest", ""},
+		"docker.io/abema/nginx:1":   {DefaultRegistry, "abema/nginx", "1", ""},
+		"gcr.io/project/app:v1":     {"gcr.io", "project/app", "v1", ""},
+		"localhost:5000/app":        {"localhost:5000", "app", "", ""},
+		"localhost:5000/app:2":      {"localhost:5000", "app", "2", ""},
+		"app@sha256:abc":            {DefaultRegistry, "library/app", "", "sha256:abc"},
+		"gcr.io/p/app:v1@sha256:ff": {"gcr.io", "p/app", "v1", "sha256:ff"},
+	}
+	for image, expected := range cases {
+		assert.Equal(t, expected, ParseReference(image), image)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/team/app/manifests/1.2.3", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Accept"), "manifest.v2+json")
+		w.Header().Set("Docker-Content-Digest", "sha256:0123")
+	}))
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	ref := ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/team/app:1.2.3")
+	digest, err := c.Digest(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:0123", digest)
+}
+
+func TestDigestFromBody(t *testing.T) {
+	manifest := `{"schemaVersion":2}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(manifest))
+	}))
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	digest, err := c.Digest(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1"))
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(manifest))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), digest)
+}
+
+func TestDigestNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	_, err := c.Digest(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1"))
+	require.Error(t, err)
+}
+
+func TestDigestSchema1(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotContains(t, r.Header.Get("Accept"), "v1+prettyjws")
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v1+prettyjws")
+		w.Write([]byte(`{"schemaVersion":1,"signatures":[]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	_, err := c.Digest(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 1")
+}
+
+func TestDigestFromBodyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// HEAD succeeds without digest, then GET fails.
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors":[{"code":"UNAUTHORIZED"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	_, err := c.Digest(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1"))
+	require.Error(t, err)
+}
+
+func TestDigestBearerToken(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "pass", pass)
+			assert.Equal(t, "repository:app:pull", r.URL.Query().Get("scope"))
+			assert.Equal(t, "test", r.URL.Query().Get("service"))
+			w.Write([]byte(`{"token":"secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test",scope="repository:app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:beef")
+	}))
+	defer srv.Close()
+
+	c := Client{
+		Insecure: true,
+		Auth: func(host string) (string, string) {
+			return "user", "pass"
+		},
+	}
+	digest, err := c.Digest(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1"))
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:beef", digest)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, "https://auth.docker.io/token", params["realm"])
+	assert.Equal(t, "registry.docker.io", params["service"])
+	assert.Equal(t, "repository:library/nginx:pull", params["scope"])
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, "registry", params["realm"])
+}
+
+func TestTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/app/tags/list", r.URL.Path)
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/app/tags/list?last=1.0.0&n=2>; rel="next"`)
+			w.Write([]byte(`{"name":"app","tags":["0.9.0","1.0.0"]}`))
+			return
+		}
+		w.Write([]byte(`{"name":"app","tags":["latest"]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Insecure: true}
+	tags, err := c.Tags(ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/app:1.0.0"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.9.0", "1.0.0", "latest"}, tags)
+}
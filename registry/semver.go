@@ -0,0 +1,234 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is semantic version parsed from image tag.
+type Version struct {
+	// Numbers of dot separated part like [1 9 1] from "1.9.1".
+	Numbers []int
+	// Pre release part after "-" like "rc.1".
+	Pre string
+	// Tag is original string.
+	Tag string
+}
+
+// ParseVersion parses tag like "1.9.1", "v2.0" or "1.0.0-rc.1".
+// Build metadata after "+" is ignored. ok is false when tag is not a version.
+func ParseVersion(tag string) (v Version, ok bool) {
+	v.Tag = tag
+	s := strings.TrimPrefix(tag, "v")
+	if plus := strings.IndexByte(s, '+'); plus >= 0 {
+		s = s[:plus]
+	}
+	if dash := strings.IndexByte(s, '-'); dash >= 0 {
+		v.Pre = s[dash+1:]
+		s = s[:dash]
+	}
+	if s == "" {
+		return v, false
+	}
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.Numbers = append(v.Numbers, n)
+	}
+	return v, true
+}
+
+// IsPre returns true when version is pre release.
+func (v Version) IsPre() bool {
+	return v.Pre != ""
+}
+
+// Compare returns -1, 0 or 1 when v is older, same or newer than o.
+func (v Version) Compare(o Version) int {
+	for i := 0; i < len(v.Numbers) || i < len(o.Numbers); i++ {
+		a, b := v.num(i), o.num(i)
+		if a != b {
+			return cmpInt(a, b)
+		}
+	}
+	// release is newer than pre release.
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+	return comparePre(v.Pre, o.Pre)
+}
+
+func (v Version) num(i int) int {
+	if i < len(v.Numbers) {
+		return v.Numbers[i]
+	}
+	return 0
+}
+
+// comparePre compares dot separated pre release identifiers.
+func comparePre(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		switch {
+		case aerr == nil && berr == nil:
+			if an != bn {
+				return cmpInt(an, bn)
+			}
+		case aerr == nil:
+			return -1
+		case berr == nil:
+			return 1
+		case as[i] != bs[i]:
+			if as[i] < bs[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return cmpInt(len(as), len(bs))
+}
+
+func cmpInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// SortTags sorts tags newest first. Versions come first in semver order and
+// other tags like "latest" follow in alphabetical order.
+func SortTags(tags []string) {
+	sort.Stable(byVersion(tags))
+}
+
+type byVersion []string
+
+func (s byVersion) Len() int      { return len(s) }
+func (s byVersion) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byVersion) Less(i, j int) bool {
+	vi, iok := ParseVersion(s[i])
+	vj, jok := ParseVersion(s[j])
+	switch {
+	case iok && jok:
+		if c := vi.Compare(vj); c != 0 {
+			return c > 0
+		}
+		return s[i] < s[j]
+	case iok:
+		return true
+	case jok:
+		return false
+	}
+	return s[i] < s[j]
+}
+
+// Bump kinds of Constraint.
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// Constraint picks target version from candidate tags.
+type Constraint struct {
+	// Bump is one of BumpPatch, BumpMinor or BumpMajor.
+	// Newest version keeping more significant numbers of current is picked.
+	Bump string
+	// Match is pattern like "1.9.x". Newest version matching it is picked.
+	Match string
+	// Pre allows pre release versions.
+	Pre bool
+}
+
+// Latest returns newest tag satisfying constraint against current tag.
+func (c Constraint) Latest(current string, tags []string) (tag string, err error) {
+	cur, ok := ParseVersion(current)
+	if !ok && c.Bump != "" {
+		return "", fmt.Errorf("current version %q is not semver", current)
+	}
+	keep := 0
+	switch c.Bump {
+	case "":
+	case BumpPatch:
+		keep = 2
+	case BumpMinor:
+		keep = 1
+	case BumpMajor:
+		keep = 0
+	default:
+		return "", fmt.Errorf("unknown bump kind %q", c.Bump)
+	}
+
+	var best *Version
+	for _, t := range tags {
+		v, ok := ParseVersion(t)
+		if !ok || (v.IsPre() && !c.Pre) {
+			continue
+		}
+		if c.Bump != "" && (!sameNumbers(v, cur, keep) || v.Compare(cur) <= 0) {
+			continue
+		}
+		if c.Match != "" && !MatchPattern(c.Match, v) {
+			continue
+		}
+		if best == nil || v.Compare(*best) > 0 {
+			found := v
+			best = &found
+		}
+	}
+	if best == nil {
+		if c.Match != "" {
+			return "", fmt.Errorf("no version matches %s", c.Match)
+		}
+		return "", fmt.Errorf("no newer %s version than %s", c.Bump, current)
+	}
+	return best.Tag, nil
+}
+
+// sameNumbers checks first n numbers of versions are same.
+func sameNumbers(a Version, b Version, n int) bool {
+	for i := 0; i < n; i++ {
+		if a.num(i) != b.num(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchPattern checks version matches pattern like "1.9.x", "1.*" or "v2".
+// Parts omitted in pattern match any number.
+func MatchPattern(pattern string, v Version) bool {
+	pattern = strings.TrimPrefix(pattern, "v")
+	for i, part := range strings.Split(pattern, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || i >= len(v.Numbers) || v.Numbers[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDowngrade returns true when target is older version than current.
+// Non semver tags are never detected as downgrade.
+func IsDowngrade(current string, target string) bool {
+	cur, cok := ParseVersion(current)
+	tgt, tok := ParseVersion(target)
+	return cok && tok && tgt.Compare(cur) < 0
+}
@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, ok := ParseVersion("v1.9.1-rc.1+build")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 9, 1}, v.Numbers)
+	assert.Equal(t, "rc.1", v.Pre)
+	assert.True(t, v.IsPre())
+
+	for _, tag := range []string{"latest", "", "v", "1.x", "alpine"} {
+		_, ok = ParseVersion(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		c    int
+	}{
+		{"1.9.1", "1.9.0", 1},
+		{"1.9", "1.9.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-1", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+	}
+	for _, c := range cases {
+		a, _ := ParseVersion(c.a)
+		b, _ := ParseVersion(c.b)
+		assert.Equal(t, c.c, a.Compare(b), c.a+" <> "+c.b)
+		assert.Equal(t, -c.c, b.Compare(a), c.b+" <> "+c.a)
+	}
+}
+
+func TestSortTags(t *testing.T) {
+	tags := []string{"latest", "1.9.0", "1.10.0", "1.10.0-rc.1", "alpine", "v1.9.5"}
+	SortTags(tags)
+	assert.Equal(t, []string{"1.10.0", "1.10.0-rc.1", "v1.9.5", "1.9.0", "alpine", "latest"}, tags)
+}
+
+func TestConstraintLatest(t *testing.T) {
+	tags := []string{"1.9.0", "1.9.1", "1.9.3", "1.9.4-rc.1", "1.10.0", "1.10.2", "2.0.0", "2.1.0-beta", "latest"}
+	cases := []struct {
+		current string
+		c       Constraint
+		tag     string
+	}{
+		{"1.9.1", Constraint{Bump: BumpPatch}, "1.9.3"},
+		{"1.9.1", Constraint{Bump: BumpPatch, Pre: true}, "1.9.4-rc.1"},
+		{"1.9.1", Constraint{Bump: BumpMinor}, "1.10.2"},
+		{"1.9.1", Constraint{Bump: BumpMajor}, "2.0.0"},
+		{"1.9.1", Constraint{Bump: BumpMajor, Pre: true}, "2.1.0-beta"},
+		{"2.0.0", Constraint{Match: "1.9.x"}, "1.9.3"},
+		{"latest", Constraint{Match: "1.*"}, "1.10.2"},
+	}
+	for _, c := range cases {
+		tag, err := c.c.Latest(c.current, tags)
+		assert.NoError(t, err, c.current)
+		assert.Equal(t, c.tag, tag, c.current)
+	}
+
+	_, err := Constraint{Bump: BumpPatch}.Latest("2.0.0", tags)
+	assert.Error(t, err)
+	_, err = Constraint{Bump: BumpPatch}.Latest("latest", tags)
+	assert.Error(t, err)
+	_, err = Constraint{Match: "3.x"}.Latest("2.0.0", tags)
+	assert.Error(t, err)
+}
+
+func TestIsDowngrade(t *testing.T) {
+	assert.True(t, IsDowngrade("1.10.0", "1.9.3"))
+	assert.False(t, IsDowngrade("1.9.3", "1.10.0"))
+	assert.False(t, IsDowngrade("latest", "1.0.0"))
+}